@@ -0,0 +1,66 @@
+package main
+
+import (
+	"context"
+	"log"
+	"log/slog"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/aparcar/asu/builder/internal/builder"
+	"github.com/aparcar/asu/builder/internal/config"
+	"github.com/aparcar/asu/builder/internal/logging"
+	"github.com/aparcar/asu/builder/internal/queue"
+	"github.com/aparcar/asu/builder/internal/rpc"
+)
+
+// runRunner runs the "runner" subcommand: it has no database of its own,
+// only a container runtime (see builder.Builder), and leases, executes, and
+// reports on build jobs against a "serve" process (see runServe) reachable
+// at config.RunnerServerURL. Multiple runner processes can point at the
+// same serve process to scale out build execution independently of the API
+// server.
+func runRunner() {
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		log.Fatalf("Failed to load configuration: %v", err)
+	}
+
+	if err := cfg.Validate(); err != nil {
+		log.Fatalf("Invalid configuration: %v", err)
+	}
+
+	slog.SetDefault(logging.New(cfg))
+
+	slog.Info("Starting ASU Builder (Go) runner",
+		"worker_id", cfg.WorkerID,
+		"runner_server_url", cfg.RunnerServerURL,
+	)
+
+	bldr, err := builder.NewBuilder(cfg)
+	if err != nil {
+		log.Fatalf("Failed to initialize builder: %v", err)
+	}
+	slog.Info("Builder initialized successfully")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	client := rpc.NewClient(cfg.RunnerServerURL, cfg.WorkerID, cfg.WorkerToken)
+	runner := queue.NewRemoteWorker(client, bldr, cfg)
+	go runner.Start(ctx)
+
+	go func() {
+		sigCh := make(chan os.Signal, 1)
+		signal.Notify(sigCh, os.Interrupt, syscall.SIGTERM)
+		<-sigCh
+
+		slog.Info("Received shutdown signal, shutting down gracefully...")
+		cancel()
+		runner.Stop()
+		os.Exit(0)
+	}()
+
+	<-ctx.Done()
+}
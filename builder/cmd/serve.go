@@ -0,0 +1,218 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"log/slog"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/aparcar/asu/builder/internal/api"
+	"github.com/aparcar/asu/builder/internal/auth"
+	"github.com/aparcar/asu/builder/internal/builder"
+	"github.com/aparcar/asu/builder/internal/config"
+	"github.com/aparcar/asu/builder/internal/db"
+	"github.com/aparcar/asu/builder/internal/jobqueue"
+	"github.com/aparcar/asu/builder/internal/logging"
+	"github.com/aparcar/asu/builder/internal/pkgupdates"
+	"github.com/aparcar/asu/builder/internal/queue"
+	"github.com/aparcar/asu/builder/internal/telemetry"
+	"golang.org/x/term"
+)
+
+// runServe runs the "serve" subcommand: it owns the database and job
+// queue, runs an in-process Worker (see queue.Worker), and serves the HTTP
+// API. Out-of-process "runner" processes (see runRunner) can additionally
+// lease jobs from it over the rpc package, so the two job sources share the
+// same database-backed queue without stepping on each other.
+func runServe() {
+	fs := flag.NewFlagSet("serve", flag.ExitOnError)
+	createAdminFlag := fs.Bool("create-admin", false, "prompt for a username/password, create that account as an admin, and exit")
+	fs.Parse(os.Args[2:])
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		log.Fatalf("Failed to load configuration: %v", err)
+	}
+
+	if err := cfg.Validate(); err != nil {
+		log.Fatalf("Invalid configuration: %v", err)
+	}
+
+	// Every package logs through slog.Default() (see the logging package),
+	// so set it up before constructing anything that might log.
+	slog.SetDefault(logging.New(cfg))
+
+	slog.Info("Starting ASU Builder (Go) serve",
+		"database_path", cfg.DatabasePath,
+		"store_path", cfg.StorePath,
+		"server_host", cfg.ServerHost,
+		"server_port", cfg.ServerPort,
+	)
+
+	// Initialize database
+	database, err := db.NewDB(cfg.DatabasePath)
+	if err != nil {
+		log.Fatalf("Failed to initialize database: %v", err)
+	}
+	defer database.Close()
+	slog.Info("Database initialized successfully")
+
+	authSvc, err := auth.New(auth.Config{
+		JWTSecret:         cfg.AuthJWTSecret,
+		JWTExpirySeconds:  cfg.AuthJWTExpirySeconds,
+		AllowRegistration: cfg.AuthAllowRegistration,
+		LoginRateLimitRPM: cfg.AuthLoginRateLimitRPM,
+	}, database)
+	if err != nil {
+		log.Fatalf("Failed to initialize auth: %v", err)
+	}
+
+	if *createAdminFlag {
+		createAdminAccount(authSvc)
+		return
+	}
+
+	// Initialize builder
+	bldr, err := builder.NewBuilder(cfg)
+	if err != nil {
+		log.Fatalf("Failed to initialize builder: %v", err)
+	}
+	slog.Info("Builder initialized successfully")
+
+	// Create context for graceful shutdown
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// Job queue backend (see the jobqueue package): nil unless
+	// cfg.QueueBackend == "asynq", in which case Redis takes over
+	// scheduling from the worker's own poll ticker below.
+	jobQueue, err := jobqueue.New(jobqueue.Config{
+		Backend:             cfg.QueueBackend,
+		RedisAddr:           cfg.QueueRedisAddr,
+		RedisPassword:       cfg.QueueRedisPassword,
+		RedisDB:             cfg.QueueRedisDB,
+		HighPriorityTargets: cfg.QueueHighPriorityTargets,
+	})
+	if err != nil {
+		log.Fatalf("Failed to initialize job queue: %v", err)
+	}
+
+	// Prometheus metrics, shared between the API server and the worker so
+	// build durations recorded by the worker show up on the same /metrics
+	// endpoint the API exposes.
+	metrics := telemetry.New(cfg.MetricsNamespace, cfg.MetricsSubsystem)
+
+	// OpenTelemetry tracing: a no-op shutdown func when no OTLP endpoint is
+	// configured (see telemetry.InitTracing). Every package traces via
+	// otel.Tracer(...) directly, the same way every package logs via
+	// slog.Default().
+	shutdownTracing, err := telemetry.InitTracing(ctx, "asu-builder", cfg.MetricsOTLPEndpoint)
+	if err != nil {
+		log.Fatalf("Failed to initialize tracing: %v", err)
+	}
+
+	// Start an in-process worker so "serve" keeps working standalone,
+	// exactly as it did before the runner split; dedicated "runner"
+	// processes lease from the same queue alongside it. With the asynq
+	// backend enabled, Redis drives scheduling instead of this poll
+	// ticker, so the worker only runs asynq's task handler.
+	worker := queue.NewWorker(database, bldr, cfg, metrics)
+	if jobQueue == nil {
+		slog.Info("Starting worker(s)", "worker_concurrent", cfg.WorkerConcurrent)
+		go worker.Start(ctx)
+	} else {
+		slog.Info("Starting asynq-backed worker(s)", "worker_concurrent", cfg.WorkerConcurrent)
+		go func() {
+			if err := jobQueue.RunServer(ctx, cfg.WorkerConcurrent, worker.ProcessJobByHash); err != nil {
+				slog.Error("Asynq worker server stopped", "error", err)
+			}
+		}()
+	}
+
+	// Start HTTP API server
+	slog.Info("Starting HTTP server", "server_host", cfg.ServerHost, "server_port", cfg.ServerPort)
+	apiServer := api.NewServer(database, cfg, bldr.Prewarmer(), bldr.BuildCache(), jobQueue, metrics, authSvc)
+
+	// Start the lease sweeper so crashed/partitioned workers and runners
+	// don't wedge jobs
+	go apiServer.StartLeaseSweeper(ctx)
+
+	// Start the ImageBuilder prewarmer so recently-built targets stay pulled
+	go apiServer.StartImageBuilderWarmer(ctx)
+
+	// Start the image GC so pulled ImageBuilder images don't accumulate
+	// forever on a long-lived worker
+	go apiServer.StartImageGC(ctx)
+
+	// Start webhook delivery so CI systems and dashboards hear about
+	// terminal build events without polling
+	go apiServer.StartWebhookDelivery(ctx)
+
+	// Start the build cache sweeper so the shared download cache and
+	// ccache don't grow unbounded on a long-lived worker
+	go apiServer.StartBuildCacheSweeper(ctx)
+
+	// Start the package update checker so builds whose pinned package
+	// versions have fallen behind upstream are flagged (and, if
+	// cfg.AutoRebuildOnUpdate is set, automatically rebuilt)
+	pkgChecker := pkgupdates.New(database, cfg.UpstreamURL, jobQueue, cfg.AutoRebuildOnUpdate)
+	go pkgChecker.Start(ctx, time.Duration(cfg.PackageIndexCheckIntervalSeconds)*time.Second)
+
+	// Handle graceful shutdown
+	go func() {
+		sigCh := make(chan os.Signal, 1)
+		signal.Notify(sigCh, os.Interrupt, syscall.SIGTERM)
+		<-sigCh
+
+		slog.Info("Received shutdown signal, shutting down gracefully...")
+		cancel()
+		worker.Stop()
+		if err := jobQueue.Close(); err != nil {
+			slog.Error("Failed to close job queue", "error", err)
+		}
+		if err := shutdownTracing(context.Background()); err != nil {
+			slog.Error("Failed to shut down tracing", "error", err)
+		}
+		os.Exit(0)
+	}()
+
+	// Start server (blocking)
+	if err := apiServer.Start(); err != nil {
+		log.Fatalf("Failed to start server: %v", err)
+	}
+}
+
+// createAdminAccount implements "serve --create-admin": it prompts for a
+// username and password on stdin/stdout rather than taking them as flags,
+// so the password never ends up in shell history or a process listing.
+func createAdminAccount(authSvc *auth.Service) {
+	reader := bufio.NewReader(os.Stdin)
+
+	fmt.Print("Admin username: ")
+	username, err := reader.ReadString('\n')
+	if err != nil {
+		log.Fatalf("Failed to read username: %v", err)
+	}
+	username = strings.TrimSpace(username)
+
+	fmt.Print("Admin password: ")
+	passwordBytes, err := term.ReadPassword(int(os.Stdin.Fd()))
+	fmt.Println()
+	if err != nil {
+		log.Fatalf("Failed to read password: %v", err)
+	}
+
+	user, err := authSvc.CreateAdmin(username, string(passwordBytes))
+	if err != nil {
+		log.Fatalf("Failed to create admin account: %v", err)
+	}
+
+	fmt.Printf("Created admin account %q (id %d)\n", user.Username, user.ID)
+}
@@ -1,30 +1,96 @@
 package api
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"log/slog"
 	"net/http"
+	"strconv"
 	"time"
 
+	"github.com/aparcar/asu/builder/internal/artifacts"
+	"github.com/aparcar/asu/builder/internal/auth"
+	"github.com/aparcar/asu/builder/internal/buildcache"
 	"github.com/aparcar/asu/builder/internal/config"
+	"github.com/aparcar/asu/builder/internal/container"
 	"github.com/aparcar/asu/builder/internal/db"
+	"github.com/aparcar/asu/builder/internal/events"
+	"github.com/aparcar/asu/builder/internal/jobqueue"
+	"github.com/aparcar/asu/builder/internal/logging"
 	"github.com/aparcar/asu/builder/internal/models"
 	"github.com/aparcar/asu/builder/internal/queue"
+	"github.com/aparcar/asu/builder/internal/signer"
+	"github.com/aparcar/asu/builder/internal/telemetry"
+	"github.com/aparcar/asu/builder/internal/webhooks"
 	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
 )
 
 // Server holds the API server components
 type Server struct {
-	db     *db.DB
-	config *config.Config
-	router *gin.Engine
+	db         *db.DB
+	config     *config.Config
+	router     *gin.Engine
+	logs       *logStreamRegistry
+	events     *eventStreamRegistry
+	prewarmer  *container.Prewarmer
+	verifier   *signer.Verifier
+	bus        *events.Bus
+	webhooks   *webhooks.Deliverer
+	buildCache *buildcache.Manager
+	jobQueue   *jobqueue.Queue // nil if config.QueueBackend == ""
+	metrics    *telemetry.Metrics
+	auth       *auth.Service
+	logger     *slog.Logger
+
+	artifactStore artifacts.Store // nil if no artifact store is configured (config.ArtifactStoreBackend == "")
 }
 
-// NewServer creates a new API server
-func NewServer(database *db.DB, cfg *config.Config) *Server {
+// NewServer creates a new API server. Logging goes through slog.Default(),
+// which runServe (cmd/serve.go) configures from cfg via logging.New before
+// any component is constructed.
+func NewServer(database *db.DB, cfg *config.Config, prewarmer *container.Prewarmer, buildCache *buildcache.Manager, jq *jobqueue.Queue, metrics *telemetry.Metrics, authSvc *auth.Service) *Server {
+	logger := slog.Default()
+
+	verifier, err := signer.NewVerifier(cfg.TrustedSigningKeysPath, cfg.TrustedSigstoreRootPath, cfg.SigstoreAllowedIdentities)
+	if err != nil {
+		logger.Warn("Artifact signature verification disabled", "error", err)
+		verifier = &signer.Verifier{}
+	}
+
+	artifactStore, err := artifacts.New(artifacts.Config{
+		Backend:       cfg.ArtifactStoreBackend,
+		Endpoint:      cfg.ArtifactStoreEndpoint,
+		Bucket:        cfg.ArtifactStoreBucket,
+		Region:        cfg.ArtifactStoreRegion,
+		AccessKey:     cfg.ArtifactStoreAccessKey,
+		SecretKey:     cfg.ArtifactStoreSecretKey,
+		UseSSL:        cfg.ArtifactStoreUseSSL,
+		PresignExpiry: time.Duration(cfg.ArtifactPresignExpirySecs) * time.Second,
+	})
+	if err != nil {
+		logger.Warn("Artifact store disabled", "error", err)
+	}
+
 	s := &Server{
-		db:     database,
-		config: cfg,
+		db:            database,
+		config:        cfg,
+		logs:          newLogStreamRegistry(cfg.LogStreamBufferLines),
+		events:        newEventStreamRegistry(cfg.LogStreamBufferLines),
+		prewarmer:     prewarmer,
+		verifier:      verifier,
+		bus:           events.NewBus(cfg.EventStreamBufferLines, cfg.EventHistoryLimit),
+		webhooks:      webhooks.New(database, cfg.WebhookURL, cfg.WebhookSecret, webhookPolicy(cfg)),
+		buildCache:    buildCache,
+		jobQueue:      jq,
+		metrics:       metrics,
+		auth:          authSvc,
+		logger:        logger,
+		artifactStore: artifactStore,
 	}
 
 	// Setup router
@@ -34,7 +100,19 @@ func NewServer(database *db.DB, cfg *config.Config) *Server {
 		gin.SetMode(gin.ReleaseMode)
 	}
 
-	s.router = gin.Default()
+	s.router = gin.New()
+	// Only trust X-Forwarded-For/X-Real-Ip from cfg.TrustedProxyCIDRs (empty
+	// by default). Without this gin trusts every direct peer's forwarded
+	// headers, so gin.Context.ClientIP() — and with it the per-IP login
+	// rate limiter (see auth.ipRateLimiter) — can be spoofed by sending a
+	// different header on every request.
+	if err := s.router.SetTrustedProxies(cfg.TrustedProxyCIDRs); err != nil {
+		logger.Warn("Invalid trusted_proxy_cidrs, trusting no proxies", "error", err)
+		s.router.SetTrustedProxies(nil)
+	}
+	s.router.Use(gin.Recovery())
+	s.router.Use(logging.RequestLogger(s.logger))
+	s.router.Use(s.metrics.GinMiddleware())
 	s.setupRoutes()
 
 	return s
@@ -42,21 +120,93 @@ func NewServer(database *db.DB, cfg *config.Config) *Server {
 
 // setupRoutes configures the API routes
 func (s *Server) setupRoutes() {
+	// Authentication (see the auth package): login/register are public,
+	// everything else that needs a role is gated per-route below.
+	s.router.POST("/api/login", s.handleLogin)
+	s.router.POST("/api/register", s.handleRegister)
+
+	// Package update checking (see the pkgupdates package)
+	s.router.GET("/api/builds/:request_hash/updates", s.handleBuildUpdates)
+
 	// API v1 routes
 	v1 := s.router.Group("/api/v1")
 	{
-		v1.POST("/build", s.handleBuildRequest)
+		v1.POST("/build", s.requireAuth(), s.handleBuildRequest)
+		v1.GET("/builds/mine", s.requireAuth(), s.handleMyBuilds)
 		v1.GET("/build/:request_hash", s.handleBuildStatus)
+		v1.DELETE("/build/:request_hash", s.requireAuth(), s.handleCancelBuild)
+		v1.GET("/build/:request_hash/logs", s.handleStreamBuildLogs)
+		v1.GET("/build/:request_hash/logs.txt", s.handleBuildLogsText)
+		v1.GET("/build/:request_hash/events", s.handleStreamBuildEvents)
+		v1.GET("/build/:request_hash/signature/:filename", s.handleGetSignature)
+		v1.POST("/verify", s.handleVerifyArtifact)
 		v1.GET("/stats", s.handleStats)
 		v1.GET("/builds-per-day", s.handleBuildsPerDay)
 		v1.GET("/builds-by-version", s.handleBuildsByVersion)
 		v1.GET("/diff-packages-stats", s.handleDiffPackagesStats)
 		v1.GET("/diff-packages-by-version", s.handleDiffPackagesByVersion)
 		v1.GET("/diff-packages-trend", s.handleDiffPackagesTrend)
+		v1.GET("/build-metrics", s.handleBuildMetrics)
+		v1.GET("/imagebuilders", s.handleImageBuilders)
+		v1.GET("/events", s.handleStreamEvents)
+		v1.GET("/cache/stats", s.handleCacheStats)
+		v1.GET("/runners", s.handleListRunners)
+		v1.GET("/queue/stats", s.handleQueueStats)
+	}
+
+	// Admin routes: operator actions on the asynq dead-letter queue (see
+	// the jobqueue package), gated to the "admin" role (see the auth
+	// package).
+	admin := s.router.Group("/api/v1/admin")
+	{
+		admin.POST("/queue/:queue_name/:request_hash/requeue", s.requireRole(models.RoleAdmin), s.handleRequeueBuild)
+		admin.POST("/queue/:queue_name/:request_hash/kill", s.requireRole(models.RoleAdmin), s.handleKillBuild)
+	}
+
+	// Internal routes, only ever called by our own workers
+	internal := s.router.Group("/api/v1/internal")
+	{
+		internal.POST("/build/:request_hash/logs", s.requireWorkerToken(), s.handleIngestBuildLog)
+		internal.POST("/build/:request_hash/logs/close", s.requireWorkerToken(), s.handleCloseBuildLogs)
+		internal.POST("/build/:request_hash/events", s.requireWorkerToken(), s.handleIngestBuildEvent)
+		internal.POST("/build/:request_hash/events/close", s.requireWorkerToken(), s.handleCloseBuildEvents)
+		internal.GET("/build/:request_hash/cancel-requested", s.requireWorkerToken(), s.handleCancelRequested)
+		internal.POST("/events", s.requireWorkerToken(), s.handleIngestEvent)
+
+		// Distributed worker protocol (see the rpc package and cmd/runner):
+		// lets a runner process lease, renew, and report on build jobs
+		// without direct database access.
+		internal.POST("/lease", s.requireWorkerToken(), s.handleLeaseBuild)
+		internal.POST("/build/:request_hash/lease/extend", s.requireWorkerToken(), s.handleExtendLease)
+		internal.POST("/build/:request_hash/complete", s.requireWorkerToken(), s.handleCompleteBuild)
+		internal.POST("/build/:request_hash/fail", s.requireWorkerToken(), s.handleFailBuild)
+		internal.POST("/runners/heartbeat", s.requireWorkerToken(), s.handleRunnerHeartbeat)
 	}
 
 	// Health check
 	s.router.GET("/health", s.handleHealth)
+
+	// Prometheus metrics (see the telemetry package)
+	s.router.GET("/metrics", gin.WrapH(s.metrics.Handler()))
+}
+
+// requireWorkerToken validates the X-Worker-Token header against
+// config.Config.WorkerToken. If no worker token is configured, the check is
+// skipped (useful for local development).
+func (s *Server) requireWorkerToken() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if s.config.WorkerToken == "" {
+			c.Next()
+			return
+		}
+
+		if c.GetHeader("X-Worker-Token") != s.config.WorkerToken {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "invalid or missing worker token"})
+			return
+		}
+
+		c.Next()
+	}
 }
 
 // Start starts the HTTP server
@@ -65,8 +215,40 @@ func (s *Server) Start() error {
 	return s.router.Run(addr)
 }
 
+// StartLeaseSweeper periodically scans for build jobs whose worker lease has
+// expired and reclaims them (requeuing or, past the retry budget, failing
+// them), so a crashed or network-partitioned worker cannot indefinitely hold
+// a request hash. It blocks until ctx is cancelled, so callers should run it
+// in a goroutine.
+func (s *Server) StartLeaseSweeper(ctx context.Context) {
+	interval := time.Duration(s.config.LeaseSweepIntervalSeconds) * time.Second
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	s.logger.Info("Lease sweeper started", "event_type", "lease_sweeper_started", "interval", interval.String())
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			reclaimed, err := s.db.ReclaimStuckJobs(s.config.MaxLeaseAttempts)
+			if err != nil {
+				s.logger.Error("Lease sweep failed", "event_type", "lease_sweep_failed", "error", err)
+				continue
+			}
+			if reclaimed > 0 {
+				s.logger.Info("Lease sweep reclaimed stuck job(s)", "event_type", "lease_sweep_reclaimed", "count", reclaimed)
+			}
+		}
+	}
+}
+
 // handleBuildRequest handles POST /api/v1/build
 func (s *Server) handleBuildRequest(c *gin.Context) {
+	ctx, span := otel.Tracer("internal/api").Start(c.Request.Context(), "build_request")
+	defer span.End()
+
 	var req models.BuildRequest
 
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -77,11 +259,28 @@ func (s *Server) handleBuildRequest(c *gin.Context) {
 	// Set created timestamp
 	req.CreatedAt = time.Now()
 
+	// Track who submitted this build (see handleMyBuilds) regardless of
+	// whatever Client the request body set.
+	req.Client = authenticatedClaims(c).Username
+
 	// Compute request hash
 	req.RequestHash = req.ComputeHash()
+	logging.WithRequestHash(c, req.RequestHash)
+	span.SetAttributes(
+		attribute.String("request_hash", req.RequestHash),
+		attribute.String("target", req.Target),
+		attribute.String("profile", req.Profile),
+		attribute.String("version", req.Version),
+	)
+
+	if req.DiffPackages {
+		s.metrics.RecordDiffPackagesUsage()
+	}
 
 	// Check if result already exists (cache hit)
+	_, dedupSpan := otel.Tracer("internal/api").Start(ctx, "dedup_lookup")
 	result, err := s.db.GetBuildResult(req.RequestHash)
+	dedupSpan.End()
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("Failed to check cache: %v", err)})
 		return
@@ -89,7 +288,8 @@ func (s *Server) handleBuildRequest(c *gin.Context) {
 
 	if result != nil {
 		// Cache hit - return existing result
-		s.db.RecordEvent(models.EventTypeCacheHit, req.Version, req.Target, req.Profile, 0, req.DiffPackages)
+		s.metrics.RecordCacheHit()
+		s.db.RecordEvent(models.EventTypeCacheHit, req.Version, req.Target, req.Profile, 0, req.DiffPackages, nil)
 
 		var images []string
 		json.Unmarshal([]byte(result.Images), &images)
@@ -139,6 +339,8 @@ func (s *Server) handleBuildRequest(c *gin.Context) {
 		return
 	}
 
+	s.metrics.RecordCacheMiss()
+
 	// Save build request
 	exists, err := s.db.BuildRequestExists(req.RequestHash)
 	if err != nil {
@@ -147,20 +349,36 @@ func (s *Server) handleBuildRequest(c *gin.Context) {
 	}
 
 	if !exists {
-		if err := s.db.CreateBuildRequest(&req); err != nil {
+		if err := s.db.CreateBuildRequest(ctx, &req); err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("Failed to save request: %v", err)})
 			return
 		}
 	}
 
 	// Enqueue job
-	if err := queue.EnqueueJob(s.db, &req); err != nil {
+	if err := queue.EnqueueJob(ctx, s.db, s.jobQueue, &req); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("Failed to enqueue job: %v", err)})
 		return
 	}
 
+	if newQueueLen, err := s.db.GetQueueLength(); err == nil {
+		s.metrics.SetQueueLength(newQueueLen)
+	}
+
 	// Record request stat
-	s.db.RecordEvent(models.EventTypeRequest, req.Version, req.Target, req.Profile, 0, req.DiffPackages)
+	s.db.RecordEvent(models.EventTypeRequest, req.Version, req.Target, req.Profile, 0, req.DiffPackages, nil)
+
+	s.publishEvent(events.TypeJobEnqueued, req.RequestHash, gin.H{"version": req.Version, "target": req.Target, "profile": req.Profile})
+
+	s.logger.Info("Build enqueued",
+		"event_type", "job_enqueued",
+		"request_hash", req.RequestHash,
+		"distro", req.Distro,
+		"version", req.Version,
+		"target", req.Target,
+		"profile", req.Profile,
+		"client", c.ClientIP(),
+	)
 
 	position, _ := s.db.GetQueuePosition(req.RequestHash)
 	response := models.BuildResponse{
@@ -172,9 +390,33 @@ func (s *Server) handleBuildRequest(c *gin.Context) {
 	c.JSON(http.StatusAccepted, response)
 }
 
+// presignArtifactURLs turns the artifact -> store key map persisted in
+// models.BuildResult.ArtifactURLs into artifact -> fresh presigned URL, so a
+// client always gets a link valid from now rather than whatever was valid
+// at upload time (see artifacts.Store.URL). If no artifact store is
+// configured, or presigning a key fails, that entry is dropped rather than
+// handed back stale or broken.
+func (s *Server) presignArtifactURLs(ctx context.Context, keys map[string]string) map[string]string {
+	if s.artifactStore == nil || len(keys) == 0 {
+		return nil
+	}
+
+	urls := make(map[string]string, len(keys))
+	for artifact, key := range keys {
+		url, err := s.artifactStore.URL(ctx, key)
+		if err != nil {
+			s.logger.Warn("Failed to presign artifact URL", "artifact", artifact, "key", key, "error", err)
+			continue
+		}
+		urls[artifact] = url
+	}
+	return urls
+}
+
 // handleBuildStatus handles GET /api/v1/build/:request_hash
 func (s *Server) handleBuildStatus(c *gin.Context) {
 	requestHash := c.Param("request_hash")
+	logging.WithRequestHash(c, requestHash)
 
 	// Check for completed build
 	result, err := s.db.GetBuildResult(requestHash)
@@ -187,14 +429,21 @@ func (s *Server) handleBuildStatus(c *gin.Context) {
 		var images []string
 		json.Unmarshal([]byte(result.Images), &images)
 
+		var artifactKeys map[string]string
+		json.Unmarshal([]byte(result.ArtifactURLs), &artifactKeys)
+		artifactURLs := s.presignArtifactURLs(c.Request.Context(), artifactKeys)
+
 		response := models.BuildResponse{
-			RequestHash:   requestHash,
-			Status:        models.JobStatusCompleted,
-			Images:        images,
-			Manifest:      result.Manifest,
-			BuildDuration: result.BuildDurationSecs,
-			FinishedAt:    &result.BuildAt,
-			CacheHit:      result.CacheHit,
+			RequestHash:           requestHash,
+			Status:                models.JobStatusCompleted,
+			Images:                images,
+			Manifest:              result.Manifest,
+			BuildDuration:         result.BuildDurationSecs,
+			FinishedAt:            &result.BuildAt,
+			CacheHit:              result.CacheHit,
+			Signatures:            result.Signatures,
+			SigningKeyFingerprint: result.SigningKeyFingerprint,
+			ArtifactURLs:          artifactURLs,
 		}
 
 		c.JSON(http.StatusOK, response)
@@ -218,28 +467,375 @@ func (s *Server) handleBuildStatus(c *gin.Context) {
 		position, _ = s.db.GetQueuePosition(requestHash)
 	}
 
+	var logs []models.BuildLogLine
+	if job.Status == models.JobStatusBuilding || job.Status == models.JobStatusCancelling || job.Status == models.JobStatusFailed {
+		logs, err = s.db.GetBuildLogTail(requestHash, "")
+		if err != nil {
+			s.logger.Error("Failed to get build logs", "request_hash", requestHash, "error", err)
+		}
+	}
+
+	steps, err := s.db.GetSteps(requestHash)
+	if err != nil {
+		s.logger.Error("Failed to get build steps", "request_hash", requestHash, "error", err)
+	}
+
 	response := models.BuildResponse{
-		RequestHash:   requestHash,
-		Status:        job.Status,
-		QueuePosition: position,
-		ErrorMessage:  job.ErrorMessage,
-		StartedAt:     job.StartedAt,
-		FinishedAt:    job.FinishedAt,
+		RequestHash:    requestHash,
+		Status:         job.Status,
+		QueuePosition:  position,
+		ErrorMessage:   job.ErrorMessage,
+		StartedAt:      job.StartedAt,
+		FinishedAt:     job.FinishedAt,
+		LeaseExpiresAt: job.LeaseExpiresAt,
+		AttemptCount:   job.AttemptCount,
+		Logs:           logs,
+		Steps:          steps,
+	}
+
+	// Point a failed build at the specific step that caused it instead of
+	// leaving the client to guess from the flat error_message.
+	if job.Status == models.JobStatusFailed {
+		for _, step := range steps {
+			if step.Status == models.StepStatusFailed {
+				response.FailedStep = &models.StepError{
+					Step:         step.Name,
+					ErrorMessage: step.ErrorMessage,
+					ExitCode:     step.ExitCode,
+				}
+				break
+			}
+		}
 	}
 
 	// Return appropriate status code
 	switch job.Status {
-	case models.JobStatusPending, models.JobStatusBuilding:
+	case models.JobStatusPending, models.JobStatusBuilding, models.JobStatusCancelling:
 		c.JSON(http.StatusAccepted, response)
 	case models.JobStatusCompleted:
 		c.JSON(http.StatusOK, response)
-	case models.JobStatusFailed:
+	case models.JobStatusFailed, models.JobStatusCancelled:
 		c.JSON(http.StatusInternalServerError, response)
 	default:
 		c.JSON(http.StatusOK, response)
 	}
 }
 
+// handleCancelBuild handles DELETE /api/v1/build/:request_hash. A pending
+// job is cancelled outright; a building job is flagged cancelling for its
+// worker to observe and tear down. Already-finished jobs return 409.
+// request_hash is a deterministic hash of public build parameters, so it's
+// guessable by anyone who'd build the same config — only the requesting
+// client (see BuildRequest.Client) may cancel it.
+func (s *Server) handleCancelBuild(c *gin.Context) {
+	requestHash := c.Param("request_hash")
+
+	req, err := s.db.GetBuildRequest(requestHash)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to look up build request"})
+		return
+	}
+	if req == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Build not found"})
+		return
+	}
+	if req.Client != authenticatedClaims(c).Username {
+		c.JSON(http.StatusForbidden, gin.H{"error": "you may only cancel your own builds"})
+		return
+	}
+
+	status, err := s.db.CancelBuildJob(requestHash)
+	if err != nil {
+		switch {
+		case errors.Is(err, db.ErrJobNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Build not found"})
+		case errors.Is(err, db.ErrJobAlreadyFinished):
+			c.JSON(http.StatusConflict, gin.H{"error": "build has already finished and cannot be cancelled"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("Failed to cancel build: %v", err)})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"request_hash": requestHash, "status": status})
+}
+
+// handleCancelRequested handles GET /api/v1/internal/build/:request_hash/cancel-requested,
+// polled by the worker running a build to learn whether it has been asked
+// to cancel.
+func (s *Server) handleCancelRequested(c *gin.Context) {
+	requestHash := c.Param("request_hash")
+
+	requested, err := s.db.IsCancelRequested(requestHash)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check cancellation status"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"cancel_requested": requested})
+}
+
+// handleStreamBuildLogs handles GET /api/v1/build/:request_hash/logs. By
+// default it streams the build's output to the client as Server-Sent
+// Events: it first replays the persisted tail so late subscribers see
+// recent output, then streams new lines live until the job finishes or the
+// client disconnects. A request with ?since=<seq> and an Accept header
+// other than text/event-stream instead gets a single JSON response with
+// the lines persisted after that sequence number, for clients that prefer
+// polling over holding a connection open.
+func (s *Server) handleStreamBuildLogs(c *gin.Context) {
+	requestHash := c.Param("request_hash")
+	step := c.Query("step")
+
+	if since := c.Query("since"); since != "" && c.GetHeader("Accept") != "text/event-stream" {
+		s.handlePollBuildLogs(c, requestHash, since)
+		return
+	}
+
+	job, err := s.db.GetBuildJob(requestHash)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get job status"})
+		return
+	}
+	if job == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Build not found"})
+		return
+	}
+
+	tail, err := s.db.GetBuildLogTail(requestHash, step)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get build logs"})
+		return
+	}
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+
+	for _, line := range tail {
+		writeLogEvent(c.Writer, "log", line)
+	}
+
+	// Already finished: nothing more will ever be published, so just emit
+	// the terminal event and return instead of waiting around.
+	if job.Status == models.JobStatusCompleted || job.Status == models.JobStatusFailed || job.Status == models.JobStatusCancelled {
+		fmt.Fprintf(c.Writer, "event: done\ndata: {\"status\":%q}\n\n", job.Status)
+		c.Writer.Flush()
+		return
+	}
+
+	id, ch := s.logs.subscribe(requestHash)
+	defer s.logs.unsubscribe(requestHash, id)
+
+	for {
+		select {
+		case <-c.Request.Context().Done():
+			return
+		case line, ok := <-ch:
+			if !ok {
+				fmt.Fprintf(c.Writer, "event: done\ndata: {\"status\":\"%s\"}\n\n", s.finalStatus(requestHash))
+				c.Writer.Flush()
+				return
+			}
+			if step != "" && line.Step != step {
+				continue
+			}
+			writeLogEvent(c.Writer, "log", line)
+			c.Writer.Flush()
+		}
+	}
+}
+
+// handlePollBuildLogs answers the ?since=<seq> branch of
+// handleStreamBuildLogs with a single JSON response instead of an SSE
+// stream.
+func (s *Server) handlePollBuildLogs(c *gin.Context, requestHash, since string) {
+	seq, err := strconv.ParseInt(since, 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid since parameter"})
+		return
+	}
+
+	lines, err := s.db.GetBuildLogsSince(requestHash, seq)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get build logs"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"lines": lines})
+}
+
+// handleBuildLogsText handles GET /api/v1/build/:request_hash/logs.txt,
+// returning the build's persisted log tail as a flat text/plain document
+// for tools that just want the raw output (e.g. `curl` or a CI log
+// artifact) instead of parsing SSE or JSON.
+func (s *Server) handleBuildLogsText(c *gin.Context) {
+	requestHash := c.Param("request_hash")
+
+	lines, err := s.db.GetBuildLogTail(requestHash, "")
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get build logs"})
+		return
+	}
+
+	c.Header("Content-Type", "text/plain; charset=utf-8")
+	for _, line := range lines {
+		fmt.Fprintln(c.Writer, line.Text)
+	}
+}
+
+// finalStatus looks up the terminal status of a job after its log stream
+// has been closed, for inclusion in the SSE "done" event.
+func (s *Server) finalStatus(requestHash string) models.JobStatus {
+	job, err := s.db.GetBuildJob(requestHash)
+	if err != nil || job == nil {
+		return models.JobStatusFailed
+	}
+	return job.Status
+}
+
+func writeLogEvent(w io.Writer, event string, line models.BuildLogLine) {
+	data, err := json.Marshal(line)
+	if err != nil {
+		return
+	}
+	fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event, data)
+}
+
+// handleIngestBuildLog handles POST /api/v1/internal/build/:request_hash/logs,
+// called by workers to publish a single build log line.
+func (s *Server) handleIngestBuildLog(c *gin.Context) {
+	requestHash := c.Param("request_hash")
+
+	var line models.BuildLogLine
+	if err := c.ShouldBindJSON(&line); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if line.Timestamp.IsZero() {
+		line.Timestamp = time.Now()
+	}
+
+	seq, err := s.db.AppendBuildLog(requestHash, line, s.config.LogTailMaxBytes)
+	if err != nil {
+		s.logger.Error("Failed to persist build log", "request_hash", requestHash, "error", err)
+	}
+	line.Seq = seq
+
+	s.logs.publish(requestHash, line)
+	s.publishEvent(events.TypeJobLog, requestHash, line)
+
+	c.Status(http.StatusNoContent)
+}
+
+// handleCloseBuildLogs handles POST /api/v1/internal/build/:request_hash/logs/close,
+// called by workers once a job reaches a terminal state so live subscribers
+// get a clean end-of-stream instead of hanging until they time out.
+func (s *Server) handleCloseBuildLogs(c *gin.Context) {
+	requestHash := c.Param("request_hash")
+	s.logs.close(requestHash)
+	c.Status(http.StatusNoContent)
+}
+
+// handleStreamBuildEvents handles GET /api/v1/build/:request_hash/events,
+// streaming the build container's lifecycle events to the client as
+// Server-Sent Events. It first replays the persisted events so late
+// subscribers see earlier phases, then streams new ones live until the job
+// finishes or the client disconnects.
+func (s *Server) handleStreamBuildEvents(c *gin.Context) {
+	requestHash := c.Param("request_hash")
+
+	job, err := s.db.GetBuildJob(requestHash)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get job status"})
+		return
+	}
+	if job == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Build not found"})
+		return
+	}
+
+	tail, err := s.db.GetBuildContainerEvents(requestHash)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get build events"})
+		return
+	}
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+
+	for _, ev := range tail {
+		writeContainerEvent(c.Writer, "event", ev)
+	}
+
+	// Already finished: nothing more will ever be published, so just emit
+	// the terminal event and return instead of waiting around.
+	if job.Status == models.JobStatusCompleted || job.Status == models.JobStatusFailed || job.Status == models.JobStatusCancelled {
+		fmt.Fprintf(c.Writer, "event: done\ndata: {\"status\":%q}\n\n", job.Status)
+		c.Writer.Flush()
+		return
+	}
+
+	id, ch := s.events.subscribe(requestHash)
+	defer s.events.unsubscribe(requestHash, id)
+
+	for {
+		select {
+		case <-c.Request.Context().Done():
+			return
+		case ev, ok := <-ch:
+			if !ok {
+				fmt.Fprintf(c.Writer, "event: done\ndata: {\"status\":\"%s\"}\n\n", s.finalStatus(requestHash))
+				c.Writer.Flush()
+				return
+			}
+			writeContainerEvent(c.Writer, "event", ev)
+			c.Writer.Flush()
+		}
+	}
+}
+
+func writeContainerEvent(w io.Writer, event string, ev models.BuildContainerEvent) {
+	data, err := json.Marshal(ev)
+	if err != nil {
+		return
+	}
+	fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event, data)
+}
+
+// handleIngestBuildEvent handles POST /api/v1/internal/build/:request_hash/events,
+// called by workers to publish a single container lifecycle event.
+func (s *Server) handleIngestBuildEvent(c *gin.Context) {
+	requestHash := c.Param("request_hash")
+
+	var ev models.BuildContainerEvent
+	if err := c.ShouldBindJSON(&ev); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if ev.Timestamp.IsZero() {
+		ev.Timestamp = time.Now()
+	}
+
+	if err := s.db.RecordContainerEvent(requestHash, ev); err != nil {
+		s.logger.Error("Failed to persist container event", "request_hash", requestHash, "error", err)
+	}
+
+	s.events.publish(requestHash, ev)
+
+	c.Status(http.StatusNoContent)
+}
+
+// handleCloseBuildEvents handles POST /api/v1/internal/build/:request_hash/events/close,
+// called by workers once a job reaches a terminal state so live subscribers
+// get a clean end-of-stream instead of hanging until they time out.
+func (s *Server) handleCloseBuildEvents(c *gin.Context) {
+	requestHash := c.Param("request_hash")
+	s.events.close(requestHash)
+	c.Status(http.StatusNoContent)
+}
+
 // handleStats handles GET /api/v1/stats
 func (s *Server) handleStats(c *gin.Context) {
 	queueLen, err := s.db.GetQueueLength()
@@ -0,0 +1,154 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/aparcar/asu/builder/internal/auth"
+	"github.com/aparcar/asu/builder/internal/models"
+	"github.com/gin-gonic/gin"
+)
+
+// contextKeyClaims is the gin context key requireAuth stores the verified
+// *auth.Claims under, for handlers to read via authenticatedClaims.
+const contextKeyClaims = "auth_claims"
+
+// loginRequest is the body of POST /api/login and POST /api/register.
+type loginRequest struct {
+	Username string `json:"username" binding:"required"`
+	Password string `json:"password" binding:"required"`
+}
+
+type loginResponse struct {
+	Token string      `json:"token"`
+	Role  models.Role `json:"role"`
+}
+
+// handleLogin handles POST /api/login. Failed attempts (bad credentials and
+// rate-limited ones alike) get a generic 401/429 so a caller can't
+// distinguish "wrong password" from "unknown username".
+func (s *Server) handleLogin(c *gin.Context) {
+	if !s.auth.AllowLoginAttempt(c.ClientIP()) {
+		c.JSON(http.StatusTooManyRequests, gin.H{"error": "too many login attempts, try again later"})
+		return
+	}
+
+	var req loginRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	user, err := s.auth.Authenticate(req.Username, req.Password)
+	if err != nil {
+		if errors.Is(err, auth.ErrInvalidCredentials) {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid username or password"})
+			return
+		}
+		s.logger.Error("Login failed", "username", req.Username, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to authenticate"})
+		return
+	}
+
+	token, err := s.auth.IssueToken(user)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to issue token"})
+		return
+	}
+
+	c.JSON(http.StatusOK, loginResponse{Token: token, Role: user.Role})
+}
+
+// handleRegister handles POST /api/register, disabled unless
+// config.AuthAllowRegistration is set.
+func (s *Server) handleRegister(c *gin.Context) {
+	var req loginRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	user, err := s.auth.Register(req.Username, req.Password)
+	if err != nil {
+		switch {
+		case errors.Is(err, auth.ErrRegistrationDisabled):
+			c.JSON(http.StatusForbidden, gin.H{"error": "registration is disabled"})
+		case errors.Is(err, auth.ErrUsernameTaken):
+			c.JSON(http.StatusConflict, gin.H{"error": "username is already taken"})
+		default:
+			s.logger.Error("Registration failed", "username", req.Username, "error", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to register"})
+		}
+		return
+	}
+
+	token, err := s.auth.IssueToken(user)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to issue token"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, loginResponse{Token: token, Role: user.Role})
+}
+
+// requireAuth validates the Authorization: Bearer <token> header and stores
+// the resulting claims on the gin context for handlers and requireRole.
+func (s *Server) requireAuth() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		header := c.GetHeader("Authorization")
+		const prefix = "Bearer "
+		if len(header) <= len(prefix) || header[:len(prefix)] != prefix {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "missing or invalid Authorization header"})
+			return
+		}
+
+		claims, err := s.auth.ParseToken(header[len(prefix):])
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "invalid or expired token"})
+			return
+		}
+
+		c.Set(contextKeyClaims, claims)
+		c.Next()
+	}
+}
+
+// requireRole wraps requireAuth and additionally requires the authenticated
+// user to hold role.
+func (s *Server) requireRole(role models.Role) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		s.requireAuth()(c)
+		if c.IsAborted() {
+			return
+		}
+
+		claims := authenticatedClaims(c)
+		if claims.Role != role {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "insufficient permissions"})
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// authenticatedClaims returns the claims requireAuth stored on c. It must
+// only be called from a handler reached after requireAuth/requireRole.
+func authenticatedClaims(c *gin.Context) *auth.Claims {
+	return c.MustGet(contextKeyClaims).(*auth.Claims)
+}
+
+// handleMyBuilds handles GET /api/v1/builds/mine, returning the
+// authenticated user's own build request history (see BuildRequest.Client,
+// populated from the username at submission time).
+func (s *Server) handleMyBuilds(c *gin.Context) {
+	claims := authenticatedClaims(c)
+
+	requests, err := s.db.ListBuildRequestsByClient(claims.Username, 100)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to list build requests"})
+		return
+	}
+
+	c.JSON(http.StatusOK, requests)
+}
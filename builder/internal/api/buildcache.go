@@ -0,0 +1,65 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// handleCacheStats handles GET /api/v1/cache/stats, reporting the shared
+// download cache's hit rate per (version,target), see db.GetCacheStats.
+func (s *Server) handleCacheStats(c *gin.Context) {
+	stats, err := s.db.GetCacheStats()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get build cache stats"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"cache_stats": stats})
+}
+
+// StartBuildCacheSweeper periodically evicts the shared build cache's
+// least-recently-used entries once they exceed their configured size
+// bounds (see buildcache.Manager), so a long-lived worker's download cache
+// and ccache don't grow unbounded. It blocks until ctx is cancelled, so
+// callers should run it in a goroutine.
+func (s *Server) StartBuildCacheSweeper(ctx context.Context) {
+	interval := time.Duration(s.config.BuildCacheSweepIntervalSecs) * time.Second
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	s.logger.Info("Build cache sweeper started", "event_type", "build_cache_sweeper_started", "interval", interval.String())
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.sweepBuildCache()
+		}
+	}
+}
+
+func (s *Server) sweepBuildCache() {
+	entries, err := s.db.ListDownloadCacheEntries()
+	if err != nil {
+		s.logger.Error("Build cache sweeper failed to list download cache entries", "error", err)
+		return
+	}
+
+	removed, err := s.buildCache.EvictDownloadCache(entries)
+	if err != nil {
+		s.logger.Error("Build cache sweeper failed to evict download cache entries", "error", err)
+	}
+	for _, key := range removed {
+		if err := s.db.DeleteDownloadCacheEntry(key); err != nil {
+			s.logger.Error("Build cache sweeper failed to forget download cache entry", "cache_key", key, "error", err)
+		}
+	}
+
+	if err := s.buildCache.SweepCCache(); err != nil {
+		s.logger.Error("Build cache sweeper failed to sweep ccache", "error", err)
+	}
+}
@@ -0,0 +1,124 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/aparcar/asu/builder/internal/config"
+	"github.com/aparcar/asu/builder/internal/events"
+	"github.com/aparcar/asu/builder/internal/webhooks"
+	"github.com/gin-gonic/gin"
+)
+
+// handleStreamEvents handles GET /api/v1/events, a builder-wide Server-Sent
+// Events stream of job and image lifecycle events, filtered by the
+// optional type=, request_hash=, and since= (RFC3339 timestamp) query
+// parameters, modeled on Podman's /events endpoint. It first replays
+// matching history so a client that connects mid-build doesn't miss
+// earlier events, then streams new ones live until the client disconnects.
+func (s *Server) handleStreamEvents(c *gin.Context) {
+	filter := events.Filter{
+		Type:        events.Type(c.Query("type")),
+		RequestHash: c.Query("request_hash"),
+	}
+	if since := c.Query("since"); since != "" {
+		t, err := time.Parse(time.RFC3339, since)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "since must be an RFC3339 timestamp"})
+			return
+		}
+		filter.Since = t
+	}
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+
+	for _, ev := range s.bus.Replay(filter) {
+		writeEvent(c.Writer, ev)
+	}
+	c.Writer.Flush()
+
+	id, ch := s.bus.Subscribe(filter)
+	defer s.bus.Unsubscribe(id)
+
+	for {
+		select {
+		case <-c.Request.Context().Done():
+			return
+		case ev, ok := <-ch:
+			if !ok {
+				return
+			}
+			writeEvent(c.Writer, ev)
+			c.Writer.Flush()
+		}
+	}
+}
+
+func writeEvent(w io.Writer, ev events.Event) {
+	data, err := json.Marshal(ev)
+	if err != nil {
+		return
+	}
+	fmt.Fprintf(w, "event: %s\ndata: %s\n\n", ev.Type, data)
+}
+
+// handleIngestEvent handles POST /api/v1/internal/events, called by workers
+// to publish a single builder-wide lifecycle event (see queue.BusPoster).
+func (s *Server) handleIngestEvent(c *gin.Context) {
+	var ev events.Event
+	if err := c.ShouldBindJSON(&ev); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	s.publishEvent(ev.Type, ev.RequestHash, ev.Data)
+
+	c.Status(http.StatusNoContent)
+}
+
+// publishEvent publishes an event of typ for requestHash carrying data
+// (marshalled to JSON) to the bus, and, for a terminal event, queues a
+// webhook delivery if one is configured.
+func (s *Server) publishEvent(typ events.Type, requestHash string, data any) {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		payload = nil
+	}
+
+	ev := events.Event{Type: typ, RequestHash: requestHash, Timestamp: time.Now(), Data: payload}
+	s.bus.Publish(ev)
+
+	if typ.Terminal() && s.webhooks != nil {
+		body, err := json.Marshal(ev)
+		if err != nil {
+			return
+		}
+		s.webhooks.Enqueue(string(typ), requestHash, body)
+	}
+}
+
+// webhookPolicy builds the webhooks.Policy from cfg's webhook_* settings.
+func webhookPolicy(cfg *config.Config) webhooks.Policy {
+	return webhooks.Policy{
+		MaxAttempts:    cfg.WebhookMaxAttempts,
+		InitialBackoff: time.Duration(cfg.WebhookInitialBackoffSeconds) * time.Second,
+		MaxBackoff:     time.Duration(cfg.WebhookMaxBackoffSeconds) * time.Second,
+		Multiplier:     cfg.WebhookBackoffMultiplier,
+	}
+}
+
+// StartWebhookDelivery starts the webhook delivery loop if webhooks are
+// configured. It blocks until ctx is cancelled, so callers should run it in
+// a goroutine.
+func (s *Server) StartWebhookDelivery(ctx context.Context) {
+	if s.webhooks == nil {
+		return
+	}
+	s.webhooks.Start(ctx, time.Duration(s.config.WebhookPollIntervalSeconds)*time.Second)
+}
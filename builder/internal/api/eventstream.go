@@ -0,0 +1,135 @@
+package api
+
+import (
+	"log"
+	"sync"
+
+	"github.com/aparcar/asu/builder/internal/models"
+)
+
+// eventStream fans out container lifecycle events for a single
+// request_hash to its live subscribers (e.g. SSE handlers). Each
+// subscriber gets its own bounded channel so one slow consumer can't block
+// the others or the publisher.
+type eventStream struct {
+	mu          sync.Mutex
+	subscribers map[int]chan models.BuildContainerEvent
+	nextID      int
+	closed      bool
+}
+
+// eventStreamRegistry holds one eventStream per in-flight request_hash.
+type eventStreamRegistry struct {
+	mu      sync.Mutex
+	streams map[string]*eventStream
+	bufSize int
+}
+
+func newEventStreamRegistry(bufSize int) *eventStreamRegistry {
+	return &eventStreamRegistry{
+		streams: make(map[string]*eventStream),
+		bufSize: bufSize,
+	}
+}
+
+func (r *eventStreamRegistry) getOrCreate(requestHash string) *eventStream {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	s, ok := r.streams[requestHash]
+	if !ok {
+		s = &eventStream{subscribers: make(map[int]chan models.BuildContainerEvent)}
+		r.streams[requestHash] = s
+	}
+	return s
+}
+
+// publish fans ev out to every current subscriber of requestHash. It is a
+// no-op if nobody is subscribed.
+func (r *eventStreamRegistry) publish(requestHash string, ev models.BuildContainerEvent) {
+	r.mu.Lock()
+	s, ok := r.streams[requestHash]
+	r.mu.Unlock()
+	if !ok {
+		return
+	}
+	s.publish(requestHash, ev)
+}
+
+// subscribe registers a new subscriber for requestHash and returns its
+// channel and an id to later unsubscribe with.
+func (r *eventStreamRegistry) subscribe(requestHash string) (int, <-chan models.BuildContainerEvent) {
+	s := r.getOrCreate(requestHash)
+	return s.subscribe(r.bufSize)
+}
+
+func (r *eventStreamRegistry) unsubscribe(requestHash string, id int) {
+	r.mu.Lock()
+	s, ok := r.streams[requestHash]
+	r.mu.Unlock()
+	if ok {
+		s.unsubscribe(id)
+	}
+}
+
+// close terminates the stream for requestHash: every subscriber channel is
+// closed (signalling end-of-stream) and the stream is dropped from the
+// registry.
+func (r *eventStreamRegistry) close(requestHash string) {
+	r.mu.Lock()
+	s, ok := r.streams[requestHash]
+	delete(r.streams, requestHash)
+	r.mu.Unlock()
+	if ok {
+		s.close()
+	}
+}
+
+func (s *eventStream) subscribe(bufSize int) (int, <-chan models.BuildContainerEvent) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	id := s.nextID
+	s.nextID++
+	ch := make(chan models.BuildContainerEvent, bufSize)
+	if s.closed {
+		close(ch)
+		return id, ch
+	}
+	s.subscribers[id] = ch
+	return id, ch
+}
+
+func (s *eventStream) unsubscribe(id int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if ch, ok := s.subscribers[id]; ok {
+		delete(s.subscribers, id)
+		close(ch)
+	}
+}
+
+func (s *eventStream) publish(requestHash string, ev models.BuildContainerEvent) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for id, ch := range s.subscribers {
+		select {
+		case ch <- ev:
+		default:
+			log.Printf("Dropping container event for %s: subscriber %d is not keeping up", requestHash, id)
+		}
+	}
+}
+
+func (s *eventStream) close() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.closed = true
+	for id, ch := range s.subscribers {
+		close(ch)
+		delete(s.subscribers, id)
+	}
+}
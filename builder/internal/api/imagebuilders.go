@@ -0,0 +1,111 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/aparcar/asu/builder/internal/container"
+	"github.com/gin-gonic/gin"
+)
+
+// handleImageBuilders handles GET /api/v1/imagebuilders, reporting the
+// prewarm status of every ImageBuilder image the server has pulled or is
+// currently pulling.
+func (s *Server) handleImageBuilders(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"imagebuilders": s.prewarmer.List()})
+}
+
+// StartImageBuilderWarmer periodically prewarms ImageBuilder images for the
+// config's allowlisted targets plus the most recently built targets, so a
+// build request is rarely the first thing to pull its image. It blocks
+// until ctx is cancelled, so callers should run it in a goroutine.
+func (s *Server) StartImageBuilderWarmer(ctx context.Context) {
+	interval := time.Duration(s.config.PrewarmIntervalSeconds) * time.Second
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	s.logger.Info("ImageBuilder warmer started", "event_type", "imagebuilder_warmer_started", "interval", interval.String())
+
+	s.warmImageBuilders()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.warmImageBuilders()
+		}
+	}
+}
+
+func (s *Server) warmImageBuilders() {
+	for _, target := range s.config.PrewarmTargets {
+		version, subtarget, ok := strings.Cut(target, "/")
+		if !ok {
+			s.logger.Warn("Skipping malformed prewarm_targets entry", "target", target)
+			continue
+		}
+		s.warmTarget(version, subtarget)
+	}
+
+	recent, err := s.db.GetRecentBuildTargets(s.config.PrewarmRecentBuildsLimit)
+	if err != nil {
+		s.logger.Error("Failed to get recent build targets for prewarming", "error", err)
+		return
+	}
+	for _, t := range recent {
+		s.warmTarget(t.Version, t.Target)
+	}
+}
+
+func (s *Server) warmTarget(version, target string) {
+	image := container.GetImageBuilderTag(s.config.ImageBuilderRegistry, version, target)
+	if image == "" {
+		s.logger.Warn("Skipping prewarm for invalid target", "version", version, "target", target)
+		return
+	}
+	s.prewarmer.Warm(image)
+}
+
+// StartImageGC periodically prunes ImageBuilder images whose last
+// successful pull (see db.RecordImagePull) is older than
+// config.ImageGCMaxAgeSeconds, so a long-lived worker doesn't accumulate
+// every image it has ever built against. It blocks until ctx is cancelled,
+// so callers should run it in a goroutine.
+func (s *Server) StartImageGC(ctx context.Context) {
+	interval := time.Duration(s.config.ImageGCIntervalSeconds) * time.Second
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	s.logger.Info("Image GC started", "event_type", "image_gc_started", "interval", interval.String())
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.gcImages()
+		}
+	}
+}
+
+func (s *Server) gcImages() {
+	maxAge := time.Duration(s.config.ImageGCMaxAgeSeconds) * time.Second
+	stale, err := s.db.GetStaleImageTags(time.Now().Add(-maxAge))
+	if err != nil {
+		s.logger.Error("Image GC failed to list stale images", "error", err)
+		return
+	}
+
+	for _, tag := range stale {
+		if err := s.prewarmer.Prune(tag); err != nil {
+			s.logger.Error("Image GC failed to remove image", "image", tag, "error", err)
+			continue
+		}
+		if err := s.db.DeleteImagePull(tag); err != nil {
+			s.logger.Error("Image GC failed to forget image", "image", tag, "error", err)
+		}
+		s.logger.Info("Image GC removed unused image", "event_type", "image_gc_removed", "image", tag)
+	}
+}
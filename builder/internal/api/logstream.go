@@ -0,0 +1,134 @@
+package api
+
+import (
+	"log"
+	"sync"
+
+	"github.com/aparcar/asu/builder/internal/models"
+)
+
+// logStream fans out log lines for a single request_hash to its live
+// subscribers (e.g. SSE handlers). Each subscriber gets its own bounded
+// channel so one slow consumer can't block the others or the publisher.
+type logStream struct {
+	mu          sync.Mutex
+	subscribers map[int]chan models.BuildLogLine
+	nextID      int
+	closed      bool
+}
+
+// logStreamRegistry holds one logStream per in-flight request_hash.
+type logStreamRegistry struct {
+	mu      sync.Mutex
+	streams map[string]*logStream
+	bufSize int
+}
+
+func newLogStreamRegistry(bufSize int) *logStreamRegistry {
+	return &logStreamRegistry{
+		streams: make(map[string]*logStream),
+		bufSize: bufSize,
+	}
+}
+
+func (r *logStreamRegistry) getOrCreate(requestHash string) *logStream {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	s, ok := r.streams[requestHash]
+	if !ok {
+		s = &logStream{subscribers: make(map[int]chan models.BuildLogLine)}
+		r.streams[requestHash] = s
+	}
+	return s
+}
+
+// publish fans line out to every current subscriber of requestHash. It is a
+// no-op if nobody is subscribed.
+func (r *logStreamRegistry) publish(requestHash string, line models.BuildLogLine) {
+	r.mu.Lock()
+	s, ok := r.streams[requestHash]
+	r.mu.Unlock()
+	if !ok {
+		return
+	}
+	s.publish(requestHash, line)
+}
+
+// subscribe registers a new subscriber for requestHash and returns its
+// channel and an id to later unsubscribe with.
+func (r *logStreamRegistry) subscribe(requestHash string) (int, <-chan models.BuildLogLine) {
+	s := r.getOrCreate(requestHash)
+	return s.subscribe(r.bufSize)
+}
+
+func (r *logStreamRegistry) unsubscribe(requestHash string, id int) {
+	r.mu.Lock()
+	s, ok := r.streams[requestHash]
+	r.mu.Unlock()
+	if ok {
+		s.unsubscribe(id)
+	}
+}
+
+// close terminates the stream for requestHash: every subscriber channel is
+// closed (signalling end-of-stream) and the stream is dropped from the
+// registry.
+func (r *logStreamRegistry) close(requestHash string) {
+	r.mu.Lock()
+	s, ok := r.streams[requestHash]
+	delete(r.streams, requestHash)
+	r.mu.Unlock()
+	if ok {
+		s.close()
+	}
+}
+
+func (s *logStream) subscribe(bufSize int) (int, <-chan models.BuildLogLine) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	id := s.nextID
+	s.nextID++
+	ch := make(chan models.BuildLogLine, bufSize)
+	if s.closed {
+		close(ch)
+		return id, ch
+	}
+	s.subscribers[id] = ch
+	return id, ch
+}
+
+func (s *logStream) unsubscribe(id int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if ch, ok := s.subscribers[id]; ok {
+		delete(s.subscribers, id)
+		close(ch)
+	}
+}
+
+func (s *logStream) publish(requestHash string, line models.BuildLogLine) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for id, ch := range s.subscribers {
+		select {
+		case ch <- line:
+		default:
+			log.Printf("Dropping log line for %s: subscriber %d is not keeping up", requestHash, id)
+		}
+	}
+}
+
+func (s *logStream) close() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.closed = true
+	for id, ch := range s.subscribers {
+		close(ch)
+		delete(s.subscribers, id)
+	}
+}
@@ -0,0 +1,44 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/aparcar/asu/builder/internal/models"
+	"github.com/aparcar/asu/builder/internal/pkgupdates"
+	"github.com/gin-gonic/gin"
+)
+
+// handleBuildUpdates handles GET /api/builds/:request_hash/updates, listing
+// any packages a build pinned that now have a newer version in the upstream
+// index cached by the pkgupdates package. An index that hasn't been checked
+// yet (e.g. immediately after the build was submitted) returns an empty list
+// rather than an error.
+func (s *Server) handleBuildUpdates(c *gin.Context) {
+	requestHash := c.Param("request_hash")
+
+	req, err := s.db.GetBuildRequest(requestHash)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get build request"})
+		return
+	}
+	if req == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Build not found"})
+		return
+	}
+
+	cache, err := s.db.GetPackageIndexCache(req.Distro, req.Version, req.Target)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get package index cache"})
+		return
+	}
+	if cache == nil {
+		c.JSON(http.StatusOK, gin.H{"updates": []models.PackageUpdate{}, "checked": false})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"updates":         pkgupdates.Outdated(req.PackagesVersions, cache.Packages),
+		"checked":         true,
+		"last_checked_at": cache.LastCheckedAt,
+	})
+}
@@ -0,0 +1,44 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// handleQueueStats handles GET /api/v1/queue/stats, reporting asynq's
+// pending/active/retry/archived counts (see jobqueue.Queue.Stats) for the
+// stats web page. If no asynq backend is configured, every count is zero.
+func (s *Server) handleQueueStats(c *gin.Context) {
+	stats, err := s.jobQueue.Stats()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get queue stats"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"queue_stats": stats})
+}
+
+// handleRequeueBuild handles POST
+// /api/v1/admin/queue/:queue_name/:request_hash/requeue, moving an
+// archived or retry-scheduled asynq task back to pending immediately.
+func (s *Server) handleRequeueBuild(c *gin.Context) {
+	if err := s.jobQueue.Requeue(c.Param("queue_name"), c.Param("request_hash")); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"status": "requeued"})
+}
+
+// handleKillBuild handles POST
+// /api/v1/admin/queue/:queue_name/:request_hash/kill, permanently removing
+// an archived asynq task instead of leaving it to expire on its own.
+func (s *Server) handleKillBuild(c *gin.Context) {
+	if err := s.jobQueue.Kill(c.Param("queue_name"), c.Param("request_hash")); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"status": "killed"})
+}
@@ -0,0 +1,219 @@
+package api
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/aparcar/asu/builder/internal/db"
+	"github.com/aparcar/asu/builder/internal/models"
+	"github.com/aparcar/asu/builder/internal/rpc"
+	"github.com/gin-gonic/gin"
+)
+
+// handleLeaseBuild handles POST /api/v1/internal/lease, called by a runner
+// process (see cmd/runner) to claim the next pending build job. It responds
+// 204 with no body when there is nothing to lease, mirroring
+// queue.Worker.processJobs' own GetPendingJobs+StartBuildJob flow so a
+// runner behaves exactly like an in-process worker from the database's
+// point of view. Because GetPendingJobs and StartBuildJob aren't atomic
+// together, two runners can read the same candidates; StartBuildJob's
+// status-guarded UPDATE ensures only one of them actually wins the lease, so
+// this walks the candidates in order and tries the next one whenever it
+// loses that race.
+func (s *Server) handleLeaseBuild(c *gin.Context) {
+	var body struct {
+		WorkerID string `json:"worker_id" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	jobs, err := s.db.GetPendingJobs()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get pending jobs"})
+		return
+	}
+	if len(jobs) == 0 {
+		c.Status(http.StatusNoContent)
+		return
+	}
+
+	leaseDuration := time.Duration(s.config.LeaseDurationSeconds) * time.Second
+
+	var job *models.BuildJob
+	for _, candidate := range jobs {
+		if err := s.db.StartBuildJob(candidate.RequestHash, body.WorkerID, leaseDuration); err != nil {
+			if errors.Is(err, db.ErrJobAlreadyLeased) {
+				// Another runner won the race for this job; try the next one.
+				continue
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start build job"})
+			return
+		}
+		job = candidate
+		break
+	}
+	if job == nil {
+		// Every candidate was claimed by another runner between our read and
+		// our lease attempt; there's nothing left for this caller right now.
+		c.Status(http.StatusNoContent)
+		return
+	}
+	job.WorkerID = body.WorkerID
+
+	req, err := s.db.GetBuildRequest(job.RequestHash)
+	if err != nil || req == nil {
+		s.logger.Error("Failed to get build request for leased job", "request_hash", job.RequestHash, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get build request"})
+		return
+	}
+
+	c.JSON(http.StatusOK, rpc.LeasedJob{Job: job, Request: req})
+}
+
+// handleExtendLease handles POST
+// /api/v1/internal/build/:request_hash/lease/extend, polled periodically by
+// a runner to keep a long build from being reclaimed by the lease sweeper.
+func (s *Server) handleExtendLease(c *gin.Context) {
+	requestHash := c.Param("request_hash")
+
+	var body struct {
+		WorkerID string `json:"worker_id" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	leaseDuration := time.Duration(s.config.LeaseDurationSeconds) * time.Second
+	if err := s.db.RenewLease(requestHash, body.WorkerID, leaseDuration); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			c.JSON(http.StatusConflict, gin.H{"error": "lease is no longer held by this runner"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to extend lease"})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// handleCompleteBuild handles POST
+// /api/v1/internal/build/:request_hash/complete, called by a runner once a
+// build finishes successfully. It does what queue.Worker.processJob
+// otherwise writes straight to the database, so a runner needs no direct
+// database access.
+func (s *Server) handleCompleteBuild(c *gin.Context) {
+	requestHash := c.Param("request_hash")
+
+	var body rpc.CompleteBuildRequest
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	buildReq, err := s.db.GetBuildRequest(requestHash)
+	if err != nil || buildReq == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Build request not found"})
+		return
+	}
+
+	imagesJSON, err := json.Marshal(body.Images)
+	if err != nil {
+		s.logger.Error("Failed to marshal images", "request_hash", requestHash, "error", err)
+	}
+
+	result := &models.BuildResult{
+		RequestHash:           requestHash,
+		Images:                string(imagesJSON),
+		Manifest:              body.Manifest,
+		BuildAt:               time.Now(),
+		BuildDurationSecs:     body.DurationSeconds,
+		Signatures:            body.Signatures,
+		SigningKeyFingerprint: body.SigningKeyFingerprint,
+		ArtifactURLs:          body.ArtifactURLs,
+	}
+	if err := s.db.CreateBuildResult(result); err != nil {
+		s.logger.Error("Failed to save build result", "request_hash", requestHash, "error", err)
+	}
+
+	if err := s.db.CompleteBuildJob(requestHash, body.WorkerID, body.BuildCmd, body.Manifest); err != nil {
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	}
+
+	s.db.RecordEvent(models.EventTypeBuildCompleted, buildReq.Version, buildReq.Target, buildReq.Profile, body.DurationSeconds, buildReq.DiffPackages, body.Resources)
+
+	c.Status(http.StatusNoContent)
+}
+
+// handleFailBuild handles POST /api/v1/internal/build/:request_hash/fail,
+// called by a runner when a build fails or is cancelled.
+func (s *Server) handleFailBuild(c *gin.Context) {
+	requestHash := c.Param("request_hash")
+
+	var body struct {
+		WorkerID     string              `json:"worker_id" binding:"required"`
+		ErrorMessage string              `json:"error_message"`
+		Class        models.FailureClass `json:"class"`
+	}
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	policy := db.RetryPolicy{
+		MaxAttempts:    s.config.RetryMaxAttempts,
+		InitialBackoff: time.Duration(s.config.RetryInitialBackoffSeconds) * time.Second,
+		MaxBackoff:     time.Duration(s.config.RetryMaxBackoffSeconds) * time.Second,
+		Multiplier:     s.config.RetryBackoffMultiplier,
+	}
+
+	if err := s.db.FailBuildJob(requestHash, body.WorkerID, body.ErrorMessage, body.Class, policy); err != nil {
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	}
+
+	if buildReq, err := s.db.GetBuildRequest(requestHash); err == nil && buildReq != nil {
+		s.db.RecordEvent(models.EventTypeFailure, buildReq.Version, buildReq.Target, buildReq.Profile, 0, buildReq.DiffPackages, nil)
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// handleRunnerHeartbeat handles POST /api/v1/internal/runners/heartbeat,
+// called periodically by every runner process so GET /api/v1/runners can
+// report which ones are alive.
+func (s *Server) handleRunnerHeartbeat(c *gin.Context) {
+	var body struct {
+		WorkerID           string `json:"worker_id" binding:"required"`
+		CurrentRequestHash string `json:"current_request_hash"`
+	}
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := s.db.UpsertRunnerHeartbeat(body.WorkerID, body.CurrentRequestHash); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to record heartbeat"})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// handleListRunners handles GET /api/v1/runners, reporting every runner
+// process that has ever sent a heartbeat.
+func (s *Server) handleListRunners(c *gin.Context) {
+	runners, err := s.db.ListRunners()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list runners"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"runners": runners})
+}
@@ -0,0 +1,137 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+
+	"github.com/gin-gonic/gin"
+)
+
+// handleGetSignature serves one signature file produced for a completed
+// build's artifact (see signArtifacts), so a client can fetch it
+// alongside the artifact before verifying. The X-Signing-Key header
+// carries the fingerprint or certificate identity that produced it.
+func (s *Server) handleGetSignature(c *gin.Context) {
+	requestHash := c.Param("request_hash")
+	filename := c.Param("filename")
+
+	result, err := s.db.GetBuildResult(requestHash)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get build result"})
+		return
+	}
+	if result == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Build not found"})
+		return
+	}
+
+	var signatures map[string]map[string]string
+	if err := json.Unmarshal([]byte(result.Signatures), &signatures); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "No signatures available for this build"})
+		return
+	}
+
+	found := false
+outer:
+	for _, files := range signatures {
+		for _, sigName := range files {
+			if sigName == filename {
+				found = true
+				break outer
+			}
+		}
+	}
+	if !found {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Signature not found"})
+		return
+	}
+
+	data, err := os.ReadFile(filepath.Join(s.config.StorePath, requestHash, filename))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Signature file not found"})
+		return
+	}
+
+	c.Header("X-Signing-Key", result.SigningKeyFingerprint)
+	c.Data(http.StatusOK, "application/octet-stream", data)
+}
+
+// verifyRequest is the body accepted by POST /api/v1/verify. The artifact
+// is identified by the build that produced it rather than an arbitrary
+// URL, so this handler only ever reads a file this server already wrote
+// under StorePath (see handleGetSignature, which serves signatures the
+// same way).
+type verifyRequest struct {
+	RequestHash string `json:"request_hash"`
+	Filename    string `json:"filename"`
+	Signature   string `json:"signature"`
+	Certificate string `json:"certificate,omitempty"` // required for sigstore signatures
+}
+
+// verifyResponse is the response for POST /api/v1/verify.
+type verifyResponse struct {
+	Valid    bool   `json:"valid"`
+	Identity string `json:"identity,omitempty"`
+	Error    string `json:"error,omitempty"`
+}
+
+// handleVerifyArtifact reads the named artifact from a completed build's
+// result and checks signature against it using the configured trusted
+// keys (see config.TrustedSigningKeysPath/TrustedSigstoreRootPath), so a
+// client can verify a build's provenance without fetching and parsing the
+// signature itself. filename must be one of the images request_hash's
+// build actually produced, so this can't be turned into a fetch of an
+// arbitrary URL or host-local file.
+func (s *Server) handleVerifyArtifact(c *gin.Context) {
+	var req verifyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Invalid request: %v", err)})
+		return
+	}
+	if req.RequestHash == "" || req.Filename == "" || req.Signature == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "request_hash, filename, and signature are required"})
+		return
+	}
+
+	result, err := s.db.GetBuildResult(req.RequestHash)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get build result"})
+		return
+	}
+	if result == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Build not found"})
+		return
+	}
+
+	var images []string
+	json.Unmarshal([]byte(result.Images), &images)
+
+	known := false
+	for _, image := range images {
+		if image == req.Filename {
+			known = true
+			break
+		}
+	}
+	if !known {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Artifact not found for this build"})
+		return
+	}
+
+	data, err := os.ReadFile(filepath.Join(s.config.StorePath, req.RequestHash, req.Filename))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Artifact file not found"})
+		return
+	}
+
+	identity, err := s.verifier.Verify(data, []byte(req.Signature), []byte(req.Certificate))
+	if err != nil {
+		c.JSON(http.StatusOK, verifyResponse{Valid: false, Error: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, verifyResponse{Valid: true, Identity: identity})
+}
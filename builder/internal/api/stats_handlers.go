@@ -54,3 +54,28 @@ func (s *Server) handleDiffPackagesTrend(c *gin.Context) {
 
 	c.JSON(http.StatusOK, trend)
 }
+
+// handleBuildMetrics handles GET /api/v1/build-metrics, returning the
+// average resource usage recorded for builds of ?version=&target=&profile=.
+func (s *Server) handleBuildMetrics(c *gin.Context) {
+	version := c.Query("version")
+	target := c.Query("target")
+	profile := c.Query("profile")
+	if version == "" || target == "" || profile == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "version, target, and profile are required"})
+		return
+	}
+
+	metrics, err := s.db.GetBuildMetrics(version, target, profile)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get build metrics"})
+		return
+	}
+
+	if metrics == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "no build metrics recorded for this version/target/profile"})
+		return
+	}
+
+	c.JSON(http.StatusOK, metrics)
+}
@@ -7,6 +7,7 @@ import (
 	"net/http"
 
 	"github.com/aparcar/asu/builder/internal/config"
+	"github.com/aparcar/asu/builder/internal/models"
 	"github.com/aparcar/asu/builder/internal/web"
 	"github.com/gin-gonic/gin"
 )
@@ -20,11 +21,13 @@ func (s *Server) setupWebRoutes() {
 	}
 	s.router.StaticFS("/static", http.FS(staticFS))
 
-	// Web UI routes
-	s.router.GET("/", s.handleOverviewPage)
-	s.router.GET("/status", s.handleStatusPage)
-	s.router.GET("/stats", s.handleStatsPage)
-	s.router.GET("/config", s.handleConfigPage)
+	// Web UI routes: any authenticated user can view the dashboard, status,
+	// and stats pages; only admins can view the config page (see the auth
+	// package).
+	s.router.GET("/", s.requireAuth(), s.handleOverviewPage)
+	s.router.GET("/status", s.requireAuth(), s.handleStatusPage)
+	s.router.GET("/stats", s.requireAuth(), s.handleStatsPage)
+	s.router.GET("/config", s.requireRole(models.RoleAdmin), s.handleConfigPage)
 }
 
 // PageData holds common data for all pages
@@ -32,6 +35,11 @@ type PageData struct {
 	Title  string
 	Active string
 	Config *config.Config
+
+	// StalePackageRequests is the "updates available" badge on the stats
+	// page (see db.SumStalePackageRequests and the pkgupdates package); 0 on
+	// every other page.
+	StalePackageRequests int
 }
 
 // renderTemplate renders an HTML template
@@ -77,10 +85,16 @@ func (s *Server) handleStatusPage(c *gin.Context) {
 
 // handleStatsPage renders the statistics page
 func (s *Server) handleStatsPage(c *gin.Context) {
+	staleCount, err := s.db.SumStalePackageRequests()
+	if err != nil {
+		s.logger.Error("Failed to get stale package request count", "error", err)
+	}
+
 	data := PageData{
-		Title:  "Statistics",
-		Active: "stats",
-		Config: s.config,
+		Title:                "Statistics",
+		Active:               "stats",
+		Config:               s.config,
+		StalePackageRequests: staleCount,
 	}
 	s.renderTemplate(c, "layout.html", data)
 }
@@ -0,0 +1,70 @@
+// Package artifacts pushes a completed build's firmware images and
+// manifest to a remote object store (S3 or MinIO), so deployments with
+// multiple workers or a short-lived StorePath can keep artifacts durable
+// off the builder's own disk. Store only matters once
+// config.ArtifactStoreBackend is configured; the local copy Builder
+// already writes under StorePath is always kept regardless, the same way
+// signer.Signer only produces an additional signature alongside an
+// artifact rather than replacing it.
+package artifacts
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"time"
+)
+
+// Store pushes a build artifact to a remote location, returning a
+// time-limited URL a client can fetch it from (see Config.PresignExpiry) —
+// never a permanent one, since that would require making the whole bucket
+// publicly readable. Because these URLs expire, callers must persist the
+// key rather than the URL and call URL again whenever a fresh link is
+// needed (see api.Server.handleBuildStatus) — storing a presigned URL
+// itself would go stale the moment it outlives PresignExpiry.
+type Store interface {
+	// Upload reads size bytes from data and stores them under key
+	// (typically "<request_hash>/<filename>"), returning the presigned URL
+	// the object can be fetched from. data is read from disk for built
+	// images and directly from the in-memory manifest for manifest.json,
+	// so this takes a reader rather than a path.
+	Upload(ctx context.Context, key string, data io.Reader, size int64) (url string, err error)
+
+	// URL returns a fresh presigned URL for a key already passed to
+	// Upload, for regenerating a link after the one returned by Upload has
+	// expired.
+	URL(ctx context.Context, key string) (url string, err error)
+}
+
+// Config is the subset of config.Config the artifacts package needs, kept
+// separate so this package doesn't import config (matches how the signer
+// and container packages take their own narrow options structs instead of
+// the whole Config).
+type Config struct {
+	Backend string // "" or "s3"
+
+	Endpoint  string
+	Bucket    string
+	Region    string
+	AccessKey string
+	SecretKey string
+	UseSSL    bool
+
+	// PresignExpiry bounds how long a URL returned by Upload stays valid
+	// for download. The bucket is never made public: every download goes
+	// through a time-limited presigned GET.
+	PresignExpiry time.Duration
+}
+
+// New returns the Store selected by cfg.Backend, or nil if no artifact
+// store is configured (the default).
+func New(cfg Config) (Store, error) {
+	switch cfg.Backend {
+	case "":
+		return nil, nil
+	case "s3":
+		return newS3Store(cfg)
+	default:
+		return nil, fmt.Errorf("unknown artifact store backend: %s", cfg.Backend)
+	}
+}
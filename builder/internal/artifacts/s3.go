@@ -0,0 +1,79 @@
+package artifacts
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+)
+
+// s3Store uploads artifacts to an S3-compatible bucket (AWS S3 or MinIO,
+// selected purely by cfg.Endpoint).
+type s3Store struct {
+	client        *minio.Client
+	bucket        string
+	presignExpiry time.Duration
+}
+
+// newS3Store creates a MinIO client against cfg.Endpoint and verifies
+// cfg.Bucket exists, so a misconfigured artifact store is caught at
+// startup rather than on the first completed build.
+func newS3Store(cfg Config) (*s3Store, error) {
+	if cfg.Endpoint == "" {
+		return nil, fmt.Errorf("artifact_store_endpoint is required for the s3 artifact store backend")
+	}
+	if cfg.Bucket == "" {
+		return nil, fmt.Errorf("artifact_store_bucket is required for the s3 artifact store backend")
+	}
+
+	client, err := minio.New(cfg.Endpoint, &minio.Options{
+		Creds:  credentials.NewStaticV4(cfg.AccessKey, cfg.SecretKey, ""),
+		Secure: cfg.UseSSL,
+		Region: cfg.Region,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create S3 client: %w", err)
+	}
+
+	exists, err := client.BucketExists(context.Background(), cfg.Bucket)
+	if err != nil {
+		return nil, fmt.Errorf("failed to reach artifact store bucket %s: %w", cfg.Bucket, err)
+	}
+	if !exists {
+		return nil, fmt.Errorf("artifact store bucket %s does not exist", cfg.Bucket)
+	}
+
+	presignExpiry := cfg.PresignExpiry
+	if presignExpiry <= 0 {
+		presignExpiry = 7 * 24 * time.Hour
+	}
+
+	return &s3Store{client: client, bucket: cfg.Bucket, presignExpiry: presignExpiry}, nil
+}
+
+// Upload puts size bytes read from data into the bucket under key, then
+// returns a presigned GET URL valid for s.presignExpiry. The bucket itself
+// is never made public: every download is a time-limited, access-controlled
+// URL rather than a permanent one.
+func (s *s3Store) Upload(ctx context.Context, key string, data io.Reader, size int64) (string, error) {
+	if _, err := s.client.PutObject(ctx, s.bucket, key, data, size, minio.PutObjectOptions{}); err != nil {
+		return "", fmt.Errorf("failed to upload %s: %w", key, err)
+	}
+
+	return s.URL(ctx, key)
+}
+
+// URL returns a fresh presigned GET URL for an already-uploaded key, valid
+// for s.presignExpiry from now. Callers must not cache this beyond that
+// window — re-call URL to hand out a live link instead.
+func (s *s3Store) URL(ctx context.Context, key string) (string, error) {
+	url, err := s.client.PresignedGetObject(ctx, s.bucket, key, s.presignExpiry, nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to presign %s: %w", key, err)
+	}
+
+	return url.String(), nil
+}
@@ -0,0 +1,82 @@
+package auth
+
+import (
+	"fmt"
+
+	"github.com/aparcar/asu/builder/internal/models"
+)
+
+// ErrInvalidCredentials is returned by Authenticate when username doesn't
+// exist or password doesn't match, without distinguishing the two so a
+// caller can't use the error to enumerate valid usernames.
+var ErrInvalidCredentials = fmt.Errorf("invalid username or password")
+
+// ErrRegistrationDisabled is returned by Register when the service was
+// constructed with Config.AllowRegistration false.
+var ErrRegistrationDisabled = fmt.Errorf("registration is disabled")
+
+// ErrUsernameTaken is returned by Register when username already exists.
+var ErrUsernameTaken = fmt.Errorf("username is already taken")
+
+// AllowLoginAttempt reports whether ip is still within its login-attempt
+// rate limit, to be checked before Authenticate does any password work.
+func (s *Service) AllowLoginAttempt(ip string) bool {
+	return s.loginLimits.Allow(ip)
+}
+
+// Authenticate verifies username/password against the stored user and
+// returns the user on success.
+func (s *Service) Authenticate(username, password string) (*models.User, error) {
+	user, err := s.db.GetUserByUsername(username)
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up user: %w", err)
+	}
+	if user == nil {
+		return nil, ErrInvalidCredentials
+	}
+
+	ok, err := VerifyPassword(password, user.PasswordHash)
+	if err != nil {
+		return nil, fmt.Errorf("failed to verify password: %w", err)
+	}
+	if !ok {
+		return nil, ErrInvalidCredentials
+	}
+
+	return user, nil
+}
+
+// Register creates a new "user"-role account, unless registration is
+// disabled or username is already taken.
+func (s *Service) Register(username, password string) (*models.User, error) {
+	if !s.allowReg {
+		return nil, ErrRegistrationDisabled
+	}
+
+	existing, err := s.db.GetUserByUsername(username)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check existing user: %w", err)
+	}
+	if existing != nil {
+		return nil, ErrUsernameTaken
+	}
+
+	hash, err := HashPassword(password)
+	if err != nil {
+		return nil, fmt.Errorf("failed to hash password: %w", err)
+	}
+
+	return s.db.CreateUser(username, hash, models.RoleUser)
+}
+
+// CreateAdmin creates an "admin"-role account directly, bypassing the
+// AllowRegistration gate and the username-taken check Register does — used
+// by the --create-admin bootstrap flag (see cmd/serve.go). Neither this nor
+// that flag checks whether other accounts already exist.
+func (s *Service) CreateAdmin(username, password string) (*models.User, error) {
+	hash, err := HashPassword(password)
+	if err != nil {
+		return nil, fmt.Errorf("failed to hash password: %w", err)
+	}
+	return s.db.CreateUser(username, hash, models.RoleAdmin)
+}
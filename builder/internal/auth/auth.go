@@ -0,0 +1,51 @@
+// Package auth provides the account system backing the web UI and API:
+// argon2id password hashing, JWT session tokens, and per-IP login rate
+// limiting. Accounts are stored in the existing SQLite DB (see db.CreateUser
+// and db.GetUserByUsername) rather than a separate store, matching how every
+// other piece of durable state in this service lives in one database.
+package auth
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/aparcar/asu/builder/internal/db"
+)
+
+// Config is the subset of config.Config the auth package needs, kept
+// separate so this package doesn't import config (matches how the signer
+// and jobqueue packages take their own narrow options structs instead of
+// the whole Config).
+type Config struct {
+	JWTSecret         string
+	JWTExpirySeconds  int
+	AllowRegistration bool
+	LoginRateLimitRPM int // login attempts allowed per IP per minute
+}
+
+// Service issues and verifies sessions for the accounts in database.
+type Service struct {
+	db          *db.DB
+	jwtSecret   []byte
+	jwtExpiry   time.Duration
+	allowReg    bool
+	loginLimits *ipRateLimiter
+}
+
+// New returns a Service backed by database. Unlike the optional
+// signer/jobqueue/artifacts backends, auth has no "disabled" state — every
+// serve process authenticates its web and build-submission routes, so
+// cfg.JWTSecret is required.
+func New(cfg Config, database *db.DB) (*Service, error) {
+	if cfg.JWTSecret == "" {
+		return nil, fmt.Errorf("auth_jwt_secret is required")
+	}
+
+	return &Service{
+		db:          database,
+		jwtSecret:   []byte(cfg.JWTSecret),
+		jwtExpiry:   time.Duration(cfg.JWTExpirySeconds) * time.Second,
+		allowReg:    cfg.AllowRegistration,
+		loginLimits: newIPRateLimiter(cfg.LoginRateLimitRPM),
+	}, nil
+}
@@ -0,0 +1,60 @@
+package auth
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/aparcar/asu/builder/internal/db"
+	"github.com/aparcar/asu/builder/internal/models"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+func newTestService(t *testing.T) *Service {
+	t.Helper()
+	database, err := db.NewDB(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("db.NewDB: %v", err)
+	}
+	t.Cleanup(func() { database.Close() })
+
+	svc, err := New(Config{JWTSecret: "test-secret", JWTExpirySeconds: 3600}, database)
+	if err != nil {
+		t.Fatalf("auth.New: %v", err)
+	}
+	return svc
+}
+
+func TestIssueAndParseTokenRoundTrip(t *testing.T) {
+	svc := newTestService(t)
+
+	token, err := svc.IssueToken(&models.User{Username: "alice", Role: models.RoleAdmin})
+	if err != nil {
+		t.Fatalf("IssueToken: %v", err)
+	}
+
+	claims, err := svc.ParseToken(token)
+	if err != nil {
+		t.Fatalf("ParseToken: %v", err)
+	}
+	if claims.Username != "alice" || claims.Role != models.RoleAdmin {
+		t.Errorf("ParseToken returned %+v, want username alice, role admin", claims)
+	}
+}
+
+// TestParseTokenRejectsAlgNone guards against the classic JWT alg-confusion
+// attack: a token whose header claims "alg: none" (or any non-HMAC method)
+// must not verify, even though the signature check would trivially pass.
+func TestParseTokenRejectsAlgNone(t *testing.T) {
+	svc := newTestService(t)
+
+	claims := Claims{Username: "mallory", Role: models.RoleAdmin}
+	token := jwt.NewWithClaims(jwt.SigningMethodNone, claims)
+	unsigned, err := token.SignedString(jwt.UnsafeAllowNoneSignatureType)
+	if err != nil {
+		t.Fatalf("failed to build alg=none token: %v", err)
+	}
+
+	if _, err := svc.ParseToken(unsigned); err == nil {
+		t.Error("ParseToken accepted an alg=none token")
+	}
+}
@@ -0,0 +1,78 @@
+package auth
+
+import (
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+// ipRateLimiterIdleTimeout is how long an IP's limiter is kept after its
+// last login attempt before sweep considers it stale.
+const ipRateLimiterIdleTimeout = 1 * time.Hour
+
+// ipRateLimiterSweepEvery bounds how often Allow triggers a sweep of idle
+// entries, so the sweep itself doesn't add overhead to every request.
+const ipRateLimiterSweepEvery = 1000
+
+// ipRateLimiter hands out a token-bucket limiter per client IP, so one
+// abusive client can't exhaust login attempts for everyone else the way a
+// single global limiter would. Limiters are created lazily; entries idle
+// for longer than ipRateLimiterIdleTimeout are swept periodically so the
+// map can't grow without bound. This relies on api.Server trusting
+// ClientIP() only from configured reverse proxies (see
+// gin.Engine.SetTrustedProxies in api.NewServer) — otherwise "ip" is
+// attacker-controlled and the bound is meaningless.
+type ipRateLimiter struct {
+	mu       sync.Mutex
+	limiters map[string]*limiterEntry
+	rpm      int
+	seen     int
+}
+
+type limiterEntry struct {
+	limiter  *rate.Limiter
+	lastSeen time.Time
+}
+
+func newIPRateLimiter(rpm int) *ipRateLimiter {
+	if rpm < 1 {
+		rpm = 1
+	}
+	return &ipRateLimiter{
+		limiters: make(map[string]*limiterEntry),
+		rpm:      rpm,
+	}
+}
+
+// Allow reports whether ip is still within its login-attempt budget.
+func (l *ipRateLimiter) Allow(ip string) bool {
+	l.mu.Lock()
+	now := time.Now()
+
+	entry, ok := l.limiters[ip]
+	if !ok {
+		entry = &limiterEntry{limiter: rate.NewLimiter(rate.Limit(float64(l.rpm)/60.0), l.rpm)}
+		l.limiters[ip] = entry
+	}
+	entry.lastSeen = now
+
+	l.seen++
+	if l.seen >= ipRateLimiterSweepEvery {
+		l.seen = 0
+		l.sweepLocked(now)
+	}
+	l.mu.Unlock()
+
+	return entry.limiter.Allow()
+}
+
+// sweepLocked removes limiters idle for longer than ipRateLimiterIdleTimeout.
+// Callers must hold l.mu.
+func (l *ipRateLimiter) sweepLocked(now time.Time) {
+	for ip, entry := range l.limiters {
+		if now.Sub(entry.lastSeen) > ipRateLimiterIdleTimeout {
+			delete(l.limiters, ip)
+		}
+	}
+}
@@ -0,0 +1,192 @@
+// Package buildcache mounts a shared download cache and ccache directory
+// into every build container, so two builds that differ by only a package
+// or two don't each re-download the same opkg feeds and kernel sources or
+// re-link object files ccache already has. Both caches live on disk under
+// config.Config.BuildCache{DL,CCache}Dir. Manager only touches the
+// filesystem: recording hit/miss stats and sweeping the download cache's
+// database-tracked entries is the caller's job (see builder.CacheRecorder
+// and api.StartBuildCacheSweeper), the same division db.DB and
+// container.Prewarmer already have for image GC.
+package buildcache
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/aparcar/asu/builder/internal/config"
+	"github.com/aparcar/asu/builder/internal/container"
+	"github.com/aparcar/asu/builder/internal/db"
+)
+
+// Download and ccache directories as mounted inside the build container.
+// dlMountTarget matches OpenWrt's own DL_DIR default so the ImageBuilder needs no
+// extra configuration to find it.
+const (
+	dlMountTarget     = "/builder/dl"
+	ccacheMountTarget = "/builder/.ccache"
+)
+
+// Manager mounts the shared download and ccache directories into build
+// containers and keeps each one under its configured size bound.
+type Manager struct {
+	dlDir      string
+	ccacheDir  string
+	dlMaxBytes int64
+	ccMaxBytes int64
+}
+
+// NewManager creates the cache directories under cfg's configured paths and
+// returns a Manager ready to mount them into build containers.
+func NewManager(cfg *config.Config) (*Manager, error) {
+	if err := os.MkdirAll(cfg.BuildCacheDLDir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create download cache directory: %w", err)
+	}
+	if err := os.MkdirAll(cfg.BuildCacheCCacheDir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create ccache directory: %w", err)
+	}
+
+	return &Manager{
+		dlDir:      cfg.BuildCacheDLDir,
+		ccacheDir:  cfg.BuildCacheCCacheDir,
+		dlMaxBytes: cfg.BuildCacheDLMaxBytes,
+		ccMaxBytes: cfg.BuildCacheCCacheMaxBytes,
+	}, nil
+}
+
+// Mounts returns the download cache and ccache bind mounts for
+// Builder.runImage to add to a build container's ContainerRunOptions.
+func (m *Manager) Mounts() []container.Mount {
+	return []container.Mount{
+		{Source: m.dlDir, Target: dlMountTarget, Mode: container.ModeBind},
+		{Source: m.ccacheDir, Target: ccacheMountTarget, Mode: container.ModeBind},
+	}
+}
+
+// Env returns the environment variables that point the ImageBuilder at the
+// mounted caches: DL_DIR for opkg/source downloads, CCACHE_DIR for ccache's
+// storage, and USE_CCACHE=1 to switch ccache on. No PATH prefix is set.
+func (m *Manager) Env() map[string]string {
+	return map[string]string{
+		"DL_DIR":     dlMountTarget,
+		"CCACHE_DIR": ccacheMountTarget,
+		"USE_CCACHE": "1",
+	}
+}
+
+// Snapshot returns the current download cache entries keyed by file name.
+// OpenWrt already names DL_DIR entries by their own upstream content hash,
+// so the file name doubles as a cache key without the builder needing to
+// hash anything itself. Builder.Build calls this before and after a build
+// to tell whether it found everything it needed already cached (see Hit).
+func (m *Manager) Snapshot() (map[string]int64, error) {
+	entries, err := os.ReadDir(m.dlDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list download cache: %w", err)
+	}
+
+	snapshot := make(map[string]int64, len(entries))
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		snapshot[entry.Name()] = info.Size()
+	}
+	return snapshot, nil
+}
+
+// Hit reports whether after (a Snapshot taken once a build finished) added
+// no file that wasn't already present in before (a Snapshot taken ahead of
+// it), meaning the build found everything it needed already cached.
+func Hit(before, after map[string]int64) bool {
+	for name := range after {
+		if _, ok := before[name]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// EvictDownloadCache removes files from the download cache until the total
+// size of entries (ordered least-recently-used first, see
+// db.ListDownloadCacheEntries) is at or under dlMaxBytes, returning the
+// cache keys it removed so the caller can delete their db rows too.
+func (m *Manager) EvictDownloadCache(entries []db.DownloadCacheEntry) ([]string, error) {
+	if m.dlMaxBytes <= 0 {
+		return nil, nil
+	}
+
+	var total int64
+	for _, e := range entries {
+		total += e.SizeBytes
+	}
+
+	var removed []string
+	for _, e := range entries {
+		if total <= m.dlMaxBytes {
+			break
+		}
+		if err := os.Remove(filepath.Join(m.dlDir, e.CacheKey)); err != nil && !os.IsNotExist(err) {
+			return removed, fmt.Errorf("failed to remove download cache entry %s: %w", e.CacheKey, err)
+		}
+		removed = append(removed, e.CacheKey)
+		total -= e.SizeBytes
+	}
+	return removed, nil
+}
+
+// ccacheFile is one file under ccacheDir, tracked by SweepCCache for LRU
+// eviction using its mtime: ccache manages its own directory layout, so
+// unlike the download cache there's no db-tracked entry list to sort by.
+type ccacheFile struct {
+	path    string
+	size    int64
+	modTime time.Time
+}
+
+// SweepCCache removes the least-recently-modified files under the ccache
+// directory until its total size on disk is at or under ccMaxBytes.
+func (m *Manager) SweepCCache() error {
+	if m.ccMaxBytes <= 0 {
+		return nil
+	}
+
+	var files []ccacheFile
+	var total int64
+	err := filepath.Walk(m.ccacheDir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			return nil
+		}
+		files = append(files, ccacheFile{path: path, size: info.Size(), modTime: info.ModTime()})
+		total += info.Size()
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to walk ccache directory: %w", err)
+	}
+
+	if total <= m.ccMaxBytes {
+		return nil
+	}
+
+	sort.Slice(files, func(i, j int) bool { return files[i].modTime.Before(files[j].modTime) })
+	for _, f := range files {
+		if total <= m.ccMaxBytes {
+			break
+		}
+		if err := os.Remove(f.path); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to remove ccache file %s: %w", f.path, err)
+		}
+		total -= f.size
+	}
+	return nil
+}
@@ -0,0 +1,95 @@
+package buildcache
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/aparcar/asu/builder/internal/config"
+	"github.com/aparcar/asu/builder/internal/db"
+)
+
+func newTestManager(t *testing.T, dlMaxBytes, ccMaxBytes int64) *Manager {
+	t.Helper()
+	dir := t.TempDir()
+	m, err := NewManager(&config.Config{
+		BuildCacheDLDir:          filepath.Join(dir, "dl"),
+		BuildCacheCCacheDir:      filepath.Join(dir, "ccache"),
+		BuildCacheDLMaxBytes:     dlMaxBytes,
+		BuildCacheCCacheMaxBytes: ccMaxBytes,
+	})
+	if err != nil {
+		t.Fatalf("NewManager: %v", err)
+	}
+	return m
+}
+
+func TestEvictDownloadCacheRemovesLeastRecentlyUsedFirst(t *testing.T) {
+	m := newTestManager(t, 15, 0)
+
+	entries := []db.DownloadCacheEntry{
+		{CacheKey: "old.ipk", SizeBytes: 10},
+		{CacheKey: "new.ipk", SizeBytes: 10},
+	}
+	for _, e := range entries {
+		if err := os.WriteFile(filepath.Join(m.dlDir, e.CacheKey), make([]byte, e.SizeBytes), 0644); err != nil {
+			t.Fatalf("failed to seed %s: %v", e.CacheKey, err)
+		}
+	}
+
+	removed, err := m.EvictDownloadCache(entries)
+	if err != nil {
+		t.Fatalf("EvictDownloadCache: %v", err)
+	}
+	if len(removed) != 1 || removed[0] != "old.ipk" {
+		t.Fatalf("EvictDownloadCache removed %v, want [old.ipk]", removed)
+	}
+	if _, err := os.Stat(filepath.Join(m.dlDir, "old.ipk")); !os.IsNotExist(err) {
+		t.Error("old.ipk should have been removed from disk")
+	}
+	if _, err := os.Stat(filepath.Join(m.dlDir, "new.ipk")); err != nil {
+		t.Error("new.ipk should still be on disk")
+	}
+}
+
+func TestEvictDownloadCacheDisabledWhenMaxBytesIsZero(t *testing.T) {
+	m := newTestManager(t, 0, 0)
+
+	entries := []db.DownloadCacheEntry{{CacheKey: "a.ipk", SizeBytes: 1000}}
+	removed, err := m.EvictDownloadCache(entries)
+	if err != nil {
+		t.Fatalf("EvictDownloadCache: %v", err)
+	}
+	if len(removed) != 0 {
+		t.Errorf("EvictDownloadCache removed %v, want none (MaxBytes disabled)", removed)
+	}
+}
+
+func TestSweepCCacheRemovesOldestFilesUntilUnderLimit(t *testing.T) {
+	m := newTestManager(t, 0, 15)
+
+	old := filepath.Join(m.ccacheDir, "old")
+	newer := filepath.Join(m.ccacheDir, "newer")
+	if err := os.WriteFile(old, make([]byte, 10), 0644); err != nil {
+		t.Fatalf("failed to seed old: %v", err)
+	}
+	if err := os.WriteFile(newer, make([]byte, 10), 0644); err != nil {
+		t.Fatalf("failed to seed newer: %v", err)
+	}
+	oldTime := time.Now().Add(-time.Hour)
+	if err := os.Chtimes(old, oldTime, oldTime); err != nil {
+		t.Fatalf("os.Chtimes: %v", err)
+	}
+
+	if err := m.SweepCCache(); err != nil {
+		t.Fatalf("SweepCCache: %v", err)
+	}
+
+	if _, err := os.Stat(old); !os.IsNotExist(err) {
+		t.Error("the older ccache file should have been swept")
+	}
+	if _, err := os.Stat(newer); err != nil {
+		t.Error("the newer ccache file should still be on disk")
+	}
+}
@@ -12,38 +12,186 @@ import (
 	"strings"
 	"time"
 
+	"github.com/aparcar/asu/builder/internal/buildcache"
 	"github.com/aparcar/asu/builder/internal/config"
 	"github.com/aparcar/asu/builder/internal/container"
 	"github.com/aparcar/asu/builder/internal/models"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 )
 
-// Builder handles firmware building operations
+// Builder handles firmware building operations against a container
+// Runtime (Podman or Docker, see the container package).
 type Builder struct {
-	config    *config.Config
-	container *container.Manager
+	config     *config.Config
+	runtime    container.Runtime
+	prewarmer  *container.Prewarmer
+	buildCache *buildcache.Manager
 }
 
-// NewBuilder creates a new builder instance
-func NewBuilder(cfg *config.Config) *Builder {
-	return &Builder{
-		config:    cfg,
-		container: container.NewManager(cfg.ContainerRuntime),
+// NewBuilder creates a new builder instance, selecting its container
+// Runtime from cfg.ContainerRuntime.
+func NewBuilder(cfg *config.Config) (*Builder, error) {
+	runtime, err := container.NewRuntime(cfg.ContainerRuntime, cfg.ContainerSocketPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create container runtime: %w", err)
+	}
+
+	buildCache, err := buildcache.NewManager(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create build cache: %w", err)
 	}
+
+	return &Builder{
+		config:     cfg,
+		runtime:    runtime,
+		prewarmer:  container.NewPrewarmer(runtime),
+		buildCache: buildCache,
+	}, nil
+}
+
+// Prewarmer returns the Builder's image prewarm registry, so the API server
+// can expose it via GET /api/v1/imagebuilders and feed it recently-built
+// targets to pull ahead of time.
+func (b *Builder) Prewarmer() *container.Prewarmer {
+	return b.prewarmer
+}
+
+// BuildCache returns the Builder's shared build cache manager, so the API
+// server can sweep it periodically (see api.StartBuildCacheSweeper).
+func (b *Builder) BuildCache() *buildcache.Manager {
+	return b.buildCache
 }
 
 // BuildResult contains the result of a build operation
 type BuildResult struct {
-	Images         []string
-	Manifest       string
-	BuildCommand   string
-	Duration       time.Duration
-	Error          error
+	Images       []string
+	Manifest     string
+	BuildCommand string
+	Duration     time.Duration
+	Resources    container.ResourceProfile
+	Error        error
+}
+
+// StepRecorder observes per-phase transitions as Build executes, letting a
+// caller persist structured progress (see queue.dbStepRecorder) without
+// this package needing to know anything about storage.
+type StepRecorder interface {
+	StartStep(name models.StepName)
+	CompleteStep(name models.StepName)
+	FailStep(name models.StepName, err error)
+}
+
+// noopStepRecorder is used when a caller doesn't care about step tracking.
+type noopStepRecorder struct{}
+
+func (noopStepRecorder) StartStep(models.StepName)       {}
+func (noopStepRecorder) CompleteStep(models.StepName)    {}
+func (noopStepRecorder) FailStep(models.StepName, error) {}
+
+// EventRecorder observes the build container's lifecycle events (create,
+// start, died, remove) as Build executes, letting a caller relay them
+// onward (see queue.EventPoster) without this package needing to know
+// anything about storage or transport.
+type EventRecorder interface {
+	Record(ev container.Event)
 }
 
-// Build executes a firmware build
-func (b *Builder) Build(ctx context.Context, req *models.BuildRequest) *BuildResult {
+// noopEventRecorder is used when a caller doesn't care about container
+// events.
+type noopEventRecorder struct{}
+
+func (noopEventRecorder) Record(container.Event) {}
+
+// PullRecorder observes successful ImageBuilder image pulls as Build
+// executes, letting a caller persist a pulled_at timestamp per image tag
+// (see queue.dbPullRecorder) for the periodic image GC to prune stale
+// images by, without this package needing to know anything about storage.
+type PullRecorder interface {
+	RecordPull(image string, pulledAt time.Time)
+}
+
+// noopPullRecorder is used when a caller doesn't care about pull tracking.
+type noopPullRecorder struct{}
+
+func (noopPullRecorder) RecordPull(string, time.Time) {}
+
+// ProgressRecorder observes ImageBuilder pull progress updates as Build
+// executes, letting a caller publish them for live observers (see
+// queue.busProgressRecorder) without this package needing to know anything
+// about pub/sub.
+type ProgressRecorder interface {
+	RecordProgress(image string, pr container.PullProgress)
+}
+
+// noopProgressRecorder is used when a caller doesn't care about pull
+// progress.
+type noopProgressRecorder struct{}
+
+func (noopProgressRecorder) RecordProgress(string, container.PullProgress) {}
+
+// CacheRecorder observes each build's shared download-cache outcome as
+// Build executes — whether it found everything it needed already cached,
+// or had to fetch something new — letting a caller persist hit/miss stats
+// and refresh cache entry metadata (see queue.dbCacheRecorder) without this
+// package needing to know anything about storage.
+type CacheRecorder interface {
+	RecordCacheUsage(version, target string, hit bool, entries map[string]int64)
+}
+
+// noopCacheRecorder is used when a caller doesn't care about cache stats.
+type noopCacheRecorder struct{}
+
+func (noopCacheRecorder) RecordCacheUsage(string, string, bool, map[string]int64) {}
+
+// Build executes a firmware build against the configured container runtime,
+// reporting its progress through the depsolve, image, and manifest phases to
+// steps, its build container's lifecycle events to events, successful
+// ImageBuilder pulls to pulls, ImageBuilder pull progress updates to
+// progress, its shared download-cache hit/miss outcome to cache, and (for
+// the image pull and the "make image" phase, the only parts long enough to
+// matter) its live output to output. Pass nil for any of these if not
+// needed.
+func (b *Builder) Build(ctx context.Context, req *models.BuildRequest, steps StepRecorder, events EventRecorder, pulls PullRecorder, progress ProgressRecorder, cache CacheRecorder, output io.Writer) *BuildResult {
+	if steps == nil {
+		steps = noopStepRecorder{}
+	}
+	if events == nil {
+		events = noopEventRecorder{}
+	}
+	if pulls == nil {
+		pulls = noopPullRecorder{}
+	}
+	if progress == nil {
+		progress = noopProgressRecorder{}
+	}
+	if cache == nil {
+		cache = noopCacheRecorder{}
+	}
+	if output == nil {
+		output = io.Discard
+	}
+
+	ctx, span := otel.Tracer("internal/builder").Start(ctx, "builder.build",
+		trace.WithAttributes(
+			attribute.String("request_hash", req.RequestHash),
+			attribute.String("target", req.Target),
+			attribute.String("profile", req.Profile),
+			attribute.String("version", req.Version),
+		),
+	)
+	defer span.End()
+
 	startTime := time.Now()
 	result := &BuildResult{}
+	defer func() {
+		if result.Error != nil {
+			span.RecordError(result.Error)
+			span.SetStatus(codes.Error, result.Error.Error())
+		}
+	}()
 
 	// Create build directory
 	buildDir := filepath.Join(b.config.StorePath, req.RequestHash)
@@ -63,45 +211,71 @@ func (b *Builder) Build(ctx context.Context, req *models.BuildRequest) *BuildRes
 		return result
 	}
 
-	// Pull ImageBuilder image if needed
-	exists, err := b.container.ImageExists(ctx, imageTag)
-	if err != nil {
-		result.Error = fmt.Errorf("failed to check image existence: %w", err)
+	// Ensure imageTag is pulled before depsolving, joining an in-flight pull
+	// (the background warmer's or a concurrent build's for the same target)
+	// instead of letting getDefaultPackages silently kick off a redundant,
+	// silent one of its own. Progress is streamed to output as it happens.
+	onPullProgress := func(pr container.PullProgress) { progress.RecordProgress(imageTag, pr) }
+	if err := b.prewarmer.PullWithOutput(ctx, imageTag, output, onPullProgress); err != nil {
+		result.Error = fmt.Errorf("failed to pull image builder: %w", err)
 		return result
 	}
-	if !exists {
-		if err := b.container.PullImage(ctx, imageTag); err != nil {
-			result.Error = fmt.Errorf("failed to pull image: %w", err)
-			return result
-		}
-	}
+	pulls.RecordPull(imageTag, time.Now())
+
+	// Depsolve: work out which packages actually go into the image
+	steps.StartStep(models.StepDepsolve)
 
-	// Get default packages
 	defaultPackages, err := b.getDefaultPackages(ctx, imageTag, req.Profile)
 	if err != nil {
 		result.Error = fmt.Errorf("failed to get default packages: %w", err)
+		steps.FailStep(models.StepDepsolve, result.Error)
 		return result
 	}
 
-	// Apply package changes (call external service)
 	packages, err := b.applyPackageChanges(ctx, req, defaultPackages)
 	if err != nil {
 		result.Error = fmt.Errorf("failed to apply package changes: %w", err)
+		steps.FailStep(models.StepDepsolve, result.Error)
 		return result
 	}
+	steps.CompleteStep(models.StepDepsolve)
+
+	// Image: run the actual ImageBuilder build
+	steps.StartStep(models.StepImage)
 
-	// Build the image
-	manifest, buildCmd, err := b.buildImage(ctx, imageTag, buildDir, req, packages)
+	// Snapshot the shared download cache before and after the run so its
+	// hit/miss outcome can be recorded; a snapshot failure just means no
+	// stats for this build, never a build failure.
+	before, _ := b.buildCache.Snapshot()
+
+	buildCmd, resources, err := b.runImage(ctx, imageTag, buildDir, req, packages, events, output)
+	result.Resources = resources
 	if err != nil {
 		result.Error = err
+		steps.FailStep(models.StepImage, result.Error)
 		return result
 	}
+	result.BuildCommand = buildCmd
+	steps.CompleteStep(models.StepImage)
+
+	if after, err := b.buildCache.Snapshot(); err == nil {
+		cache.RecordCacheUsage(req.Version, req.Target, buildcache.Hit(before, after), after)
+	}
+
+	// Manifest: record the exact package set that ended up in the image
+	steps.StartStep(models.StepManifest)
 
+	manifest, err := b.getManifest(ctx, imageTag, req.Profile)
+	if err != nil {
+		result.Error = err
+		steps.FailStep(models.StepManifest, result.Error)
+		return result
+	}
 	result.Manifest = manifest
-	result.BuildCommand = buildCmd
+	steps.CompleteStep(models.StepManifest)
 
 	// Find built images
-	images, err := b.findBuiltImages(buildDir)
+	images, err := findBuiltImages(buildDir)
 	if err != nil {
 		result.Error = fmt.Errorf("failed to find built images: %w", err)
 		return result
@@ -115,24 +289,20 @@ func (b *Builder) Build(ctx context.Context, req *models.BuildRequest) *BuildRes
 
 // getDefaultPackages retrieves default packages for a profile
 func (b *Builder) getDefaultPackages(ctx context.Context, imageTag, profile string) ([]string, error) {
-	var stdout bytes.Buffer
-
 	opts := container.ContainerRunOptions{
 		Image:   imageTag,
 		Remove:  true,
 		Command: []string{"make", "info"},
+		Ctx:     ctx,
 	}
 
-	if err := b.container.RunCommandInContainer(ctx, opts, &stdout, io.Discard); err != nil {
+	output, err := b.runtime.RunContainer(opts)
+	if err != nil {
 		return nil, fmt.Errorf("failed to run 'make info': %w", err)
 	}
 
 	// Parse output to extract default packages
-	// The output format is typically:
-	// Default Packages: package1 package2 package3...
-	output := stdout.String()
 	lines := strings.Split(output, "\n")
-
 	for _, line := range lines {
 		if strings.HasPrefix(line, "Default Packages:") {
 			packagesStr := strings.TrimPrefix(line, "Default Packages:")
@@ -146,12 +316,12 @@ func (b *Builder) getDefaultPackages(ctx context.Context, imageTag, profile stri
 
 // PackageChangesRequest is sent to the package changes service
 type PackageChangesRequest struct {
-	Version         string            `json:"version"`
-	Target          string            `json:"target"`
-	Profile         string            `json:"profile"`
-	Packages        []string          `json:"packages"`
-	DefaultPackages []string          `json:"default_packages"`
-	DiffPackages    bool              `json:"diff_packages"`
+	Version         string   `json:"version"`
+	Target          string   `json:"target"`
+	Profile         string   `json:"profile"`
+	Packages        []string `json:"packages"`
+	DefaultPackages []string `json:"default_packages"`
+	DiffPackages    bool     `json:"diff_packages"`
 }
 
 // PackageChangesResponse is returned by the package changes service
@@ -160,9 +330,8 @@ type PackageChangesResponse struct {
 	Error    string   `json:"error,omitempty"`
 }
 
-// applyPackageChanges calls the package changes service to modify the package list
+// applyPackageChanges calls the package changes service
 func (b *Builder) applyPackageChanges(ctx context.Context, req *models.BuildRequest, defaultPackages []string) ([]string, error) {
-	// If no package changes service is configured, return packages as-is
 	if b.config.PackageChangesURL == "" {
 		return req.Packages, nil
 	}
@@ -190,8 +359,7 @@ func (b *Builder) applyPackageChanges(ctx context.Context, req *models.BuildRequ
 	client := &http.Client{Timeout: 30 * time.Second}
 	resp, err := client.Do(httpReq)
 	if err != nil {
-		// If service is unavailable, fall back to original packages
-		return req.Packages, nil
+		return req.Packages, nil // Fallback
 	}
 	defer resp.Body.Close()
 
@@ -211,8 +379,11 @@ func (b *Builder) applyPackageChanges(ctx context.Context, req *models.BuildRequ
 	return result.Packages, nil
 }
 
-// buildImage builds the firmware image
-func (b *Builder) buildImage(ctx context.Context, imageTag, buildDir string, req *models.BuildRequest, packages []string) (string, string, error) {
+// runImage runs the ImageBuilder "make image" phase, returning the build
+// command that was used and a summary of the container's resource usage.
+// Its stdout/stderr is streamed live to output as the container runs, since
+// this phase can take several minutes.
+func (b *Builder) runImage(ctx context.Context, imageTag, buildDir string, req *models.BuildRequest, packages []string, events EventRecorder, output io.Writer) (string, container.ResourceProfile, error) {
 	// Prepare build command
 	makeArgs := []string{"make", "image"}
 	makeArgs = append(makeArgs, fmt.Sprintf("PROFILE=%s", req.Profile))
@@ -227,12 +398,16 @@ func (b *Builder) buildImage(ctx context.Context, imageTag, buildDir string, req
 
 	buildCmd := strings.Join(makeArgs, " ")
 
-	// Setup mounts
+	// Setup mounts. /builder/bin only ever receives output from the
+	// container (the built images land under its targets/ subdirectory),
+	// so it's declared ModeCopyOut; /builder/files is input-only, so it's
+	// ModeCopyIn. A local runtime bind-mounts both regardless of Mode (see
+	// container.Mount); only a remote Podman socket acts on it.
 	mounts := []container.Mount{
 		{
-			Source:   buildDir,
-			Target:   "/builder/bin",
-			ReadOnly: false,
+			Source: buildDir,
+			Target: "/builder/bin",
+			Mode:   container.ModeCopyOut,
 		},
 	}
 
@@ -240,53 +415,110 @@ func (b *Builder) buildImage(ctx context.Context, imageTag, buildDir string, req
 	if req.Defaults != "" && b.config.AllowDefaults {
 		defaultsFile := filepath.Join(buildDir, "files", "etc", "uci-defaults", "99-custom")
 		if err := os.MkdirAll(filepath.Dir(defaultsFile), 0755); err != nil {
-			return "", buildCmd, fmt.Errorf("failed to create defaults directory: %w", err)
+			return buildCmd, container.ResourceProfile{}, fmt.Errorf("failed to create defaults directory: %w", err)
 		}
 		if err := os.WriteFile(defaultsFile, []byte(req.Defaults), 0755); err != nil {
-			return "", buildCmd, fmt.Errorf("failed to write defaults file: %w", err)
+			return buildCmd, container.ResourceProfile{}, fmt.Errorf("failed to write defaults file: %w", err)
 		}
 
 		mounts = append(mounts, container.Mount{
 			Source:   filepath.Join(buildDir, "files"),
 			Target:   "/builder/files",
 			ReadOnly: true,
+			Mode:     container.ModeCopyIn,
 		})
 	}
 
-	var stdout, stderr bytes.Buffer
+	// Add the shared download cache and ccache, so this build doesn't
+	// re-fetch opkg feeds or re-link object files a previous build already
+	// has. Both are plain read-write bind mounts, which a remote Podman
+	// socket can't do (see container.Mount.Mode), so they're skipped there
+	// rather than mounted one-way and silently never filled back in.
+	var env map[string]string
+	if b.runtime.SupportsBindMounts() {
+		mounts = append(mounts, b.buildCache.Mounts()...)
+		env = b.buildCache.Env()
+	} else {
+		fmt.Fprintln(output, "Shared build cache disabled: remote container runtime can't bind-mount host cache directories")
+	}
+
+	// Poll the build container's resource usage for the duration of the
+	// run, so the worker can record a CPU/memory/IO profile alongside the
+	// build's other stats.
+	collector := container.NewStatsCollector()
+	collectCtx, stopCollecting := context.WithCancel(context.Background())
+	defer stopCollecting()
+
+	// Watch the build container's lifecycle events for the duration of the
+	// run, so the worker can record precise phase timings alongside the
+	// build's other stats.
+	eventStream := container.NewEventStream(b.runtime)
+	eventCtx, stopWatchingEvents := context.WithCancel(context.Background())
+	defer stopWatchingEvents()
 
 	opts := container.ContainerRunOptions{
-		Image:   imageTag,
-		Remove:  true,
-		Mounts:  mounts,
-		Command: makeArgs,
+		Image:  imageTag,
+		Name:   fmt.Sprintf("asu-build-%s", req.RequestHash),
+		Remove: true,
+		Labels: map[string]string{
+			container.BuildHashLabel:    req.RequestHash,
+			container.BuildVersionLabel: req.Version,
+			container.BuildTargetLabel:  req.Target,
+			container.BuildProfileLabel: req.Profile,
+		},
+		Mounts:      mounts,
+		Environment: env,
+		Command:     makeArgs,
+		LogWriter:   output,
+		Ctx:         ctx,
+		OnContainerStart: func(containerID string) {
+			go collector.Run(collectCtx, b.runtime, containerID)
+			go watchEvents(eventCtx, eventStream, req.RequestHash, events)
+		},
 	}
 
-	// Run the build
-	if err := b.container.RunCommandInContainer(ctx, opts, &stdout, &stderr); err != nil {
-		return "", buildCmd, fmt.Errorf("build failed: %w\nStdout: %s\nStderr: %s", err, stdout.String(), stderr.String())
+	if _, err := b.runtime.RunContainer(opts); err != nil {
+		return buildCmd, collector.Profile(), fmt.Errorf("build failed: %w", err)
 	}
 
-	// Get manifest
-	manifestOpts := container.ContainerRunOptions{
+	return buildCmd, collector.Profile(), nil
+}
+
+// watchEvents forwards requestHash's container lifecycle events from stream
+// to events until ctx is cancelled or the stream closes (the container
+// exited and the runtime stopped reporting events for it).
+func watchEvents(ctx context.Context, stream *container.EventStream, requestHash string, events EventRecorder) {
+	ch, err := stream.Watch(ctx, requestHash)
+	if err != nil {
+		return
+	}
+	for ev := range ch {
+		events.Record(ev)
+	}
+}
+
+// getManifest runs the ImageBuilder "make manifest" phase and returns its
+// output, the exact package set that ended up in the built image.
+func (b *Builder) getManifest(ctx context.Context, imageTag, profile string) (string, error) {
+	opts := container.ContainerRunOptions{
 		Image:   imageTag,
 		Remove:  true,
-		Command: []string{"make", "manifest", fmt.Sprintf("PROFILE=%s", req.Profile)},
+		Command: []string{"make", "manifest", fmt.Sprintf("PROFILE=%s", profile)},
+		Ctx:     ctx,
 	}
 
-	var manifestOut bytes.Buffer
-	if err := b.container.RunCommandInContainer(ctx, manifestOpts, &manifestOut, io.Discard); err != nil {
-		return "", buildCmd, fmt.Errorf("failed to get manifest: %w", err)
+	manifest, err := b.runtime.RunContainer(opts)
+	if err != nil {
+		return "", fmt.Errorf("failed to get manifest: %w", err)
 	}
 
-	return manifestOut.String(), buildCmd, nil
+	return manifest, nil
 }
 
 // findBuiltImages finds all built firmware images in the build directory
-func (b *Builder) findBuiltImages(buildDir string) ([]string, error) {
+func findBuiltImages(buildDir string) ([]string, error) {
 	var images []string
 
-	// Images are typically in bin/targets/<target>/<subtarget>/
 	err := filepath.Walk(buildDir, func(path string, info os.FileInfo, err error) error {
 		if err != nil {
 			return err
@@ -296,7 +528,7 @@ func (b *Builder) findBuiltImages(buildDir string) ([]string, error) {
 			return nil
 		}
 
-		// Look for firmware image files (typically .bin, .img, .tar.gz, etc.)
+		// Look for firmware image files
 		ext := filepath.Ext(path)
 		if ext == ".bin" || ext == ".img" || ext == ".gz" || ext == ".trx" {
 			relPath, err := filepath.Rel(buildDir, path)
@@ -309,9 +541,5 @@ func (b *Builder) findBuiltImages(buildDir string) ([]string, error) {
 		return nil
 	})
 
-	if err != nil {
-		return nil, err
-	}
-
-	return images, nil
+	return images, err
 }
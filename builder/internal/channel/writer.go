@@ -0,0 +1,63 @@
+// Package channel provides a non-blocking io.Writer that fans bytes into a
+// buffered channel, for forwarding live output from a producer that must
+// never stall (e.g. a container log read loop) to a consumer that might
+// (e.g. an HTTP-backed SSE publisher).
+package channel
+
+import "sync"
+
+// Writer is an io.Writer whose Write copies its argument into a frame and
+// sends it on a buffered channel instead of writing synchronously. If the
+// channel is full the frame is dropped and Dropped is incremented rather
+// than blocking the writer.
+type Writer struct {
+	mu      sync.Mutex
+	frames  chan []byte
+	closed  bool
+	Dropped int
+}
+
+// New creates a Writer backed by a channel buffering up to capacity frames.
+func New(capacity int) *Writer {
+	return &Writer{frames: make(chan []byte, capacity)}
+}
+
+// Frames returns the channel of frames written so far. It is closed when
+// Close is called, so a range over it ends cleanly.
+func (w *Writer) Frames() <-chan []byte {
+	return w.frames
+}
+
+// Write implements io.Writer. p is copied before being queued, since the
+// caller may reuse its buffer after Write returns.
+func (w *Writer) Write(p []byte) (int, error) {
+	frame := make([]byte, len(p))
+	copy(frame, p)
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.closed {
+		return len(p), nil
+	}
+
+	select {
+	case w.frames <- frame:
+	default:
+		w.Dropped++
+	}
+
+	return len(p), nil
+}
+
+// Close signals that no more frames will arrive, closing the channel
+// returned by Frames. Safe to call more than once.
+func (w *Writer) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.closed {
+		return nil
+	}
+	w.closed = true
+	close(w.frames)
+	return nil
+}
@@ -14,39 +14,193 @@ type Config struct {
 	ServerHost string `mapstructure:"server_host"`
 	ServerPort int    `mapstructure:"server_port"`
 
+	// TrustedProxyCIDRs lists the CIDRs of reverse proxies gin should trust
+	// X-Forwarded-For/X-Real-Ip from when resolving gin.Context.ClientIP()
+	// (used by the login rate limiter, see auth.ipRateLimiter). Leave empty
+	// when the server is reachable directly, so a caller can't spoof their
+	// own IP to dodge the rate limit.
+	TrustedProxyCIDRs []string `mapstructure:"trusted_proxy_cidrs"`
+
 	// Database configuration
 	DatabasePath string `mapstructure:"database_path"`
 
 	// Storage configuration
-	PublicPath   string `mapstructure:"public_path"`
-	StorePath    string `mapstructure:"store_path"`
+	PublicPath string `mapstructure:"public_path"`
+	StorePath  string `mapstructure:"store_path"`
 
 	// Upstream OpenWrt configuration
 	UpstreamURL string `mapstructure:"upstream_url"`
 
 	// Container configuration
-	ContainerRuntime     string `mapstructure:"container_runtime"`      // podman or docker
-	ContainerSocketPath  string `mapstructure:"container_socket_path"`
+	ContainerRuntime     string `mapstructure:"container_runtime"`     // podman or docker
+	ContainerSocketPath  string `mapstructure:"container_socket_path"` // podman only: a local path, or a tcp://, ssh://, unix:// URI for a remote socket
 	ImageBuilderRegistry string `mapstructure:"imagebuilder_registry"`
 
+	// ImageBuilder prewarming: proactively pulls images for recently-built
+	// targets plus any allowlisted ones, so the first build for a target
+	// doesn't stall on an uncached pull.
+	PrewarmTargets           []string `mapstructure:"prewarm_targets"` // "version/target/subtarget", e.g. "23.05.3/ath79/generic"
+	PrewarmIntervalSeconds   int      `mapstructure:"prewarm_interval_seconds"`
+	PrewarmRecentBuildsLimit int      `mapstructure:"prewarm_recent_builds_limit"`
+
+	// ImageBuilder GC: prunes images whose last successful pull (see
+	// db.RecordImagePull) is older than ImageGCMaxAgeSeconds, so a long-lived
+	// worker doesn't accumulate every ImageBuilder image it has ever built.
+	ImageGCIntervalSeconds int `mapstructure:"image_gc_interval_seconds"`
+	ImageGCMaxAgeSeconds   int `mapstructure:"image_gc_max_age_seconds"`
+
+	// Shared build cache (see the buildcache package): a download cache and
+	// a ccache directory mounted into every build container so consecutive
+	// builds don't each re-fetch opkg feeds or re-link object files from
+	// scratch. Each is swept in the background to stay under its MaxBytes.
+	BuildCacheDLDir             string `mapstructure:"build_cache_dl_dir"`
+	BuildCacheCCacheDir         string `mapstructure:"build_cache_ccache_dir"`
+	BuildCacheDLMaxBytes        int64  `mapstructure:"build_cache_dl_max_bytes"`
+	BuildCacheCCacheMaxBytes    int64  `mapstructure:"build_cache_ccache_max_bytes"`
+	BuildCacheSweepIntervalSecs int    `mapstructure:"build_cache_sweep_interval_seconds"`
+
 	// Build configuration
-	MaxPendingJobs     int    `mapstructure:"max_pending_jobs"`
-	JobTimeoutSeconds  int    `mapstructure:"job_timeout_seconds"`
-	BuildTTLSeconds    int    `mapstructure:"build_ttl_seconds"`
-	FailureTTLSeconds  int    `mapstructure:"failure_ttl_seconds"`
-	AllowDefaults      bool   `mapstructure:"allow_defaults"`
-	BuildKey           string `mapstructure:"build_key"`
+	MaxPendingJobs    int    `mapstructure:"max_pending_jobs"`
+	JobTimeoutSeconds int    `mapstructure:"job_timeout_seconds"`
+	BuildTTLSeconds   int    `mapstructure:"build_ttl_seconds"`
+	FailureTTLSeconds int    `mapstructure:"failure_ttl_seconds"`
+	AllowDefaults     bool   `mapstructure:"allow_defaults"`
+	BuildKey          string `mapstructure:"build_key"`
+
+	// Worker lease configuration
+	LeaseDurationSeconds      int `mapstructure:"lease_duration_seconds"`
+	LeaseSweepIntervalSeconds int `mapstructure:"lease_sweep_interval_seconds"`
+	MaxLeaseAttempts          int `mapstructure:"max_lease_attempts"`
 
 	// Worker configuration
 	WorkerID         string `mapstructure:"worker_id"`
 	WorkerConcurrent int    `mapstructure:"worker_concurrent"`
 	WorkerPollSecs   int    `mapstructure:"worker_poll_seconds"`
+	WorkerToken      string `mapstructure:"worker_token"`
+
+	// Build log streaming configuration
+	LogStreamBufferLines int `mapstructure:"log_stream_buffer_lines"`
+	LogTailMaxBytes      int `mapstructure:"log_tail_max_bytes"`
+
+	// Cancellation configuration
+	CancelPollIntervalSeconds int `mapstructure:"cancel_poll_interval_seconds"`
+
+	// Retry policy for transiently failed build jobs
+	RetryMaxAttempts           int     `mapstructure:"retry_max_attempts"`
+	RetryInitialBackoffSeconds int     `mapstructure:"retry_initial_backoff_seconds"`
+	RetryMaxBackoffSeconds     int     `mapstructure:"retry_max_backoff_seconds"`
+	RetryBackoffMultiplier     float64 `mapstructure:"retry_backoff_multiplier"`
 
 	// Package changes service
 	PackageChangesURL string `mapstructure:"package_changes_url"`
 
-	// Logging
-	LogLevel string `mapstructure:"log_level"`
+	// Firmware artifact signing (see the signer package): after a
+	// successful build, every image plus the manifest is signed and the
+	// signature stored alongside it. An empty SigningBackend disables
+	// signing.
+	SigningBackend            string `mapstructure:"signing_backend"` // "", "gpg", or "sigstore"
+	SigningGPGKeyPath         string `mapstructure:"signing_gpg_key_path"`
+	SigningGPGKeyPassphrase   string `mapstructure:"signing_gpg_key_passphrase"`
+	SigstoreFulcioURL         string `mapstructure:"sigstore_fulcio_url"`
+	SigstoreRekorURL          string `mapstructure:"sigstore_rekor_url"`
+	SigstoreIdentityTokenPath string `mapstructure:"sigstore_identity_token_path"`
+	TrustedSigningKeysPath    string `mapstructure:"trusted_signing_keys_path"` // armored GPG keyring used by POST /api/v1/verify
+
+	// TrustedSigstoreRootPath is the PEM-encoded Fulcio (or Fulcio-compatible)
+	// root CA used by POST /api/v1/verify to check that a sigstore
+	// certificate chains to a trusted root, instead of trusting whatever
+	// self-signed certificate a caller supplies. Leaving it empty disables
+	// sigstore verification (GPG verification is unaffected).
+	TrustedSigstoreRootPath string `mapstructure:"trusted_sigstore_root_path"`
+	// SigstoreAllowedIdentities restricts POST /api/v1/verify to sigstore
+	// certificates whose signing identity (CommonName) is in this list. An
+	// empty list allows any identity that otherwise chains to a trusted
+	// root.
+	SigstoreAllowedIdentities []string `mapstructure:"sigstore_allowed_identities"`
+
+	// Build lifecycle event bus (see the events package): buffers job and
+	// image events for GET /api/v1/events and feeds webhook delivery.
+	EventStreamBufferLines int `mapstructure:"event_stream_buffer_lines"`
+	EventHistoryLimit      int `mapstructure:"event_history_limit"`
+
+	// Webhook delivery: an empty WebhookURL disables webhooks. Configured
+	// deliveries are retried with exponential backoff (see the webhooks
+	// package) until WebhookMaxAttempts is exhausted.
+	WebhookURL                   string  `mapstructure:"webhook_url"`
+	WebhookSecret                string  `mapstructure:"webhook_secret"` // HMAC-SHA256 key for the X-Asu-Signature header
+	WebhookMaxAttempts           int     `mapstructure:"webhook_max_attempts"`
+	WebhookInitialBackoffSeconds int     `mapstructure:"webhook_initial_backoff_seconds"`
+	WebhookMaxBackoffSeconds     int     `mapstructure:"webhook_max_backoff_seconds"`
+	WebhookBackoffMultiplier     float64 `mapstructure:"webhook_backoff_multiplier"`
+	WebhookPollIntervalSeconds   int     `mapstructure:"webhook_poll_interval_seconds"`
+
+	// Logging: structured log output (see the logging package), in addition
+	// to LogLevel also gating gin's own debug/release mode.
+	LogLevel  string `mapstructure:"log_level"`
+	LogFormat string `mapstructure:"log_format"` // "text" or "json"
+	LogFile   string `mapstructure:"log_file"`   // empty logs to stderr only
+
+	// Artifact store (see the artifacts package): after a successful build,
+	// every image plus the manifest is additionally pushed to an S3/MinIO
+	// bucket, alongside the copy Builder already wrote under StorePath. An
+	// empty ArtifactStoreBackend disables this (the local copy is still
+	// served from StorePath as today).
+	ArtifactStoreBackend      string `mapstructure:"artifact_store_backend"` // "" or "s3"
+	ArtifactStoreEndpoint     string `mapstructure:"artifact_store_endpoint"`
+	ArtifactStoreBucket       string `mapstructure:"artifact_store_bucket"`
+	ArtifactStoreRegion       string `mapstructure:"artifact_store_region"`
+	ArtifactStoreAccessKey    string `mapstructure:"artifact_store_access_key"`
+	ArtifactStoreSecretKey    string `mapstructure:"artifact_store_secret_key"`
+	ArtifactStoreUseSSL       bool   `mapstructure:"artifact_store_use_ssl"`
+	ArtifactPresignExpirySecs int    `mapstructure:"artifact_presign_expiry_seconds"` // how long a presigned download URL stays valid
+
+	// Runner (see cmd/runner and the rpc package): used only by the
+	// "runner" subcommand, which executes builds out-of-process from the
+	// "serve" subcommand that owns the database and HTTP API.
+	// RunnerServerURL is the serve process's base URL, reusing WorkerToken
+	// and WorkerID above for authentication and lease ownership.
+	RunnerServerURL                string `mapstructure:"runner_server_url"`
+	RunnerHeartbeatIntervalSeconds int    `mapstructure:"runner_heartbeat_interval_seconds"`
+
+	// Job queue backend (see the jobqueue package): an empty QueueBackend
+	// keeps the SQLite-polling queue.Worker loop used today; "asynq" hands
+	// scheduling, retries, backoff, and the dead-letter queue to a
+	// Redis-backed github.com/hibiken/asynq queue instead, so multiple
+	// serve instances can share one queue. SQLite still holds build
+	// metadata and results either way. QueueHighPriorityTargets routes
+	// builds for listed targets onto asynq's "critical" queue instead of
+	// "default".
+	QueueBackend             string   `mapstructure:"queue_backend"` // "" or "asynq"
+	QueueRedisAddr           string   `mapstructure:"queue_redis_addr"`
+	QueueRedisPassword       string   `mapstructure:"queue_redis_password"`
+	QueueRedisDB             int      `mapstructure:"queue_redis_db"`
+	QueueHighPriorityTargets []string `mapstructure:"queue_high_priority_targets"`
+
+	// Observability (see the telemetry package): Prometheus metrics served
+	// at GET /metrics, namespaced "<MetricsNamespace>_<MetricsSubsystem>_*".
+	// An empty MetricsOTLPEndpoint leaves OpenTelemetry tracing disabled;
+	// metrics are always on since they're local and effectively free.
+	MetricsNamespace    string `mapstructure:"metrics_namespace"`
+	MetricsSubsystem    string `mapstructure:"metrics_subsystem"`
+	MetricsOTLPEndpoint string `mapstructure:"metrics_otlp_endpoint"`
+
+	// Authentication (see the auth package): argon2id-hashed accounts,
+	// JWTs issued by POST /api/login and verified by gin middleware on the
+	// web routes and POST /api/v1/build. AuthAllowRegistration gates
+	// whether POST /api/register is open to anyone; the first account is
+	// always bootstrapped separately via the --create-admin flag.
+	AuthJWTSecret         string `mapstructure:"auth_jwt_secret"`
+	AuthJWTExpirySeconds  int    `mapstructure:"auth_jwt_expiry_seconds"`
+	AuthAllowRegistration bool   `mapstructure:"auth_allow_registration"`
+	AuthLoginRateLimitRPM int    `mapstructure:"auth_login_rate_limit_rpm"`
+
+	// Package update checking (see the pkgupdates package): periodically
+	// fetches the upstream opkg package index for every (distro, version,
+	// target) seen in build_requests and flags builds whose pinned
+	// PackagesVersions have fallen behind. AutoRebuildOnUpdate
+	// additionally re-enqueues a fresh build for every stale request found.
+	PackageIndexCheckIntervalSeconds int  `mapstructure:"package_index_check_interval_seconds"`
+	AutoRebuildOnUpdate              bool `mapstructure:"package_index_auto_rebuild"`
 }
 
 // LoadConfig loads configuration from environment and config file
@@ -91,6 +245,7 @@ func setDefaults(v *viper.Viper) {
 	// Server defaults
 	v.SetDefault("server_host", "0.0.0.0")
 	v.SetDefault("server_port", 8080)
+	v.SetDefault("trusted_proxy_cidrs", []string{})
 
 	// Database defaults
 	v.SetDefault("database_path", "./data/builder.db")
@@ -107,25 +262,125 @@ func setDefaults(v *viper.Viper) {
 	v.SetDefault("container_socket_path", "/run/podman/podman.sock")
 	v.SetDefault("imagebuilder_registry", "ghcr.io/openwrt/imagebuilder")
 
+	// ImageBuilder prewarming defaults
+	v.SetDefault("prewarm_targets", []string{})
+	v.SetDefault("prewarm_interval_seconds", 300) // 5 minutes
+	v.SetDefault("prewarm_recent_builds_limit", 10)
+
+	// ImageBuilder GC defaults
+	v.SetDefault("image_gc_interval_seconds", 3600)   // 1 hour
+	v.SetDefault("image_gc_max_age_seconds", 7*86400) // 1 week
+
+	// Shared build cache defaults
+	v.SetDefault("build_cache_dl_dir", "./data/buildcache/dl")
+	v.SetDefault("build_cache_ccache_dir", "./data/buildcache/ccache")
+	v.SetDefault("build_cache_dl_max_bytes", 20*1024*1024*1024)    // 20 GiB
+	v.SetDefault("build_cache_ccache_max_bytes", 5*1024*1024*1024) // 5 GiB
+	v.SetDefault("build_cache_sweep_interval_seconds", 1800)       // 30 minutes
+
 	// Build defaults
 	v.SetDefault("max_pending_jobs", 200)
-	v.SetDefault("job_timeout_seconds", 600) // 10 minutes
-	v.SetDefault("build_ttl_seconds", 86400) // 1 day
+	v.SetDefault("job_timeout_seconds", 600)  // 10 minutes
+	v.SetDefault("build_ttl_seconds", 86400)  // 1 day
 	v.SetDefault("failure_ttl_seconds", 3600) // 1 hour
 	v.SetDefault("allow_defaults", true)
 	v.SetDefault("build_key", "")
 
+	// Worker lease defaults
+	v.SetDefault("lease_duration_seconds", 3600)     // 1 hour
+	v.SetDefault("lease_sweep_interval_seconds", 60) // 1 minute
+	v.SetDefault("max_lease_attempts", 3)
+
 	// Worker defaults
 	hostname, _ := os.Hostname()
 	v.SetDefault("worker_id", hostname)
 	v.SetDefault("worker_concurrent", 4)
 	v.SetDefault("worker_poll_seconds", 5)
+	v.SetDefault("worker_token", "")
+
+	// Build log streaming defaults
+	v.SetDefault("log_stream_buffer_lines", 256)
+	v.SetDefault("log_tail_max_bytes", 4*1024*1024) // 4 MiB
+
+	// Cancellation defaults
+	v.SetDefault("cancel_poll_interval_seconds", 5)
+
+	// Retry policy defaults
+	v.SetDefault("retry_max_attempts", 3)
+	v.SetDefault("retry_initial_backoff_seconds", 10)
+	v.SetDefault("retry_max_backoff_seconds", 300)
+	v.SetDefault("retry_backoff_multiplier", 2.0)
 
 	// Package changes service
 	v.SetDefault("package_changes_url", "http://localhost:8081")
 
+	// Firmware artifact signing defaults (disabled unless configured)
+	v.SetDefault("signing_backend", "")
+	v.SetDefault("signing_gpg_key_path", "")
+	v.SetDefault("signing_gpg_key_passphrase", "")
+	v.SetDefault("sigstore_fulcio_url", "")
+	v.SetDefault("sigstore_rekor_url", "")
+	v.SetDefault("sigstore_identity_token_path", "")
+	v.SetDefault("trusted_signing_keys_path", "")
+	v.SetDefault("trusted_sigstore_root_path", "")
+	v.SetDefault("sigstore_allowed_identities", []string{})
+
+	// Build lifecycle event bus defaults
+	v.SetDefault("event_stream_buffer_lines", 256)
+	v.SetDefault("event_history_limit", 1000)
+
+	// Webhook delivery defaults (disabled unless webhook_url is configured)
+	v.SetDefault("webhook_url", "")
+	v.SetDefault("webhook_secret", "")
+	v.SetDefault("webhook_max_attempts", 6)
+	v.SetDefault("webhook_initial_backoff_seconds", 5)
+	v.SetDefault("webhook_max_backoff_seconds", 300)
+	v.SetDefault("webhook_backoff_multiplier", 2.0)
+	v.SetDefault("webhook_poll_interval_seconds", 5)
+
 	// Logging
 	v.SetDefault("log_level", "info")
+	v.SetDefault("log_format", "text")
+	v.SetDefault("log_file", "")
+
+	// Artifact store defaults (disabled unless configured)
+	v.SetDefault("artifact_store_backend", "")
+	v.SetDefault("artifact_store_endpoint", "")
+	v.SetDefault("artifact_store_bucket", "")
+	v.SetDefault("artifact_store_region", "")
+	v.SetDefault("artifact_store_access_key", "")
+	v.SetDefault("artifact_store_secret_key", "")
+	v.SetDefault("artifact_store_use_ssl", true)
+	v.SetDefault("artifact_presign_expiry_seconds", 7*86400) // 7 days, minio/S3's own maximum
+
+	// Runner defaults
+	v.SetDefault("runner_server_url", "http://localhost:8080")
+	v.SetDefault("runner_heartbeat_interval_seconds", 30)
+
+	// Job queue backend defaults (disabled unless configured)
+	v.SetDefault("queue_backend", "")
+	v.SetDefault("queue_redis_addr", "localhost:6379")
+	v.SetDefault("queue_redis_password", "")
+	v.SetDefault("queue_redis_db", 0)
+	v.SetDefault("queue_high_priority_targets", []string{})
+
+	// Metrics/tracing defaults (tracing disabled unless configured)
+	v.SetDefault("metrics_namespace", "asu")
+	v.SetDefault("metrics_subsystem", "builder")
+	v.SetDefault("metrics_otlp_endpoint", "")
+
+	// Authentication defaults. auth_jwt_secret has no usable default — it
+	// must be set explicitly (see Validate) — everything else is safe to
+	// ship as-is.
+	v.SetDefault("auth_jwt_secret", "")
+	v.SetDefault("auth_jwt_expiry_seconds", 3600) // 1 hour
+	v.SetDefault("auth_allow_registration", false)
+	v.SetDefault("auth_login_rate_limit_rpm", 5)
+
+	// Package update checking defaults (auto-rebuild disabled unless
+	// configured)
+	v.SetDefault("package_index_check_interval_seconds", 21600) // 6 hours
+	v.SetDefault("package_index_auto_rebuild", false)
 }
 
 func (c *Config) expandPaths() error {
@@ -146,6 +401,16 @@ func (c *Config) expandPaths() error {
 		return fmt.Errorf("failed to expand store_path: %w", err)
 	}
 
+	c.BuildCacheDLDir, err = expandPath(c.BuildCacheDLDir)
+	if err != nil {
+		return fmt.Errorf("failed to expand build_cache_dl_dir: %w", err)
+	}
+
+	c.BuildCacheCCacheDir, err = expandPath(c.BuildCacheCCacheDir)
+	if err != nil {
+		return fmt.Errorf("failed to expand build_cache_ccache_dir: %w", err)
+	}
+
 	if c.BuildKey != "" {
 		c.BuildKey, err = expandPath(c.BuildKey)
 		if err != nil {
@@ -153,6 +418,13 @@ func (c *Config) expandPaths() error {
 		}
 	}
 
+	if c.LogFile != "" {
+		c.LogFile, err = expandPath(c.LogFile)
+		if err != nil {
+			return fmt.Errorf("failed to expand log_file: %w", err)
+		}
+	}
+
 	return nil
 }
 
@@ -197,5 +469,81 @@ func (c *Config) Validate() error {
 		return fmt.Errorf("max_pending_jobs must be at least 1")
 	}
 
+	if c.LeaseDurationSeconds < 1 {
+		return fmt.Errorf("lease_duration_seconds must be at least 1")
+	}
+
+	if c.MaxLeaseAttempts < 1 {
+		return fmt.Errorf("max_lease_attempts must be at least 1")
+	}
+
+	if c.RetryMaxAttempts < 1 {
+		return fmt.Errorf("retry_max_attempts must be at least 1")
+	}
+
+	if c.SigningBackend != "" && c.SigningBackend != "gpg" && c.SigningBackend != "sigstore" {
+		return fmt.Errorf("signing_backend must be '', 'gpg', or 'sigstore'")
+	}
+
+	if c.WebhookURL != "" && c.WebhookMaxAttempts < 1 {
+		return fmt.Errorf("webhook_max_attempts must be at least 1")
+	}
+
+	if c.BuildCacheSweepIntervalSecs < 1 {
+		return fmt.Errorf("build_cache_sweep_interval_seconds must be at least 1")
+	}
+
+	if c.LogFormat != "text" && c.LogFormat != "json" {
+		return fmt.Errorf("log_format must be 'text' or 'json'")
+	}
+
+	if c.ArtifactStoreBackend != "" && c.ArtifactStoreBackend != "s3" {
+		return fmt.Errorf("artifact_store_backend must be '' or 's3'")
+	}
+
+	if c.ArtifactStoreBackend == "s3" && (c.ArtifactStoreEndpoint == "" || c.ArtifactStoreBucket == "") {
+		return fmt.Errorf("artifact_store_endpoint and artifact_store_bucket are required when artifact_store_backend is 's3'")
+	}
+
+	if c.ArtifactPresignExpirySecs > 7*86400 {
+		return fmt.Errorf("artifact_presign_expiry_seconds must be at most 604800 (7 days, the S3/MinIO presigned URL limit)")
+	}
+
+	if c.RunnerServerURL == "" {
+		return fmt.Errorf("runner_server_url is required")
+	}
+
+	if c.RunnerHeartbeatIntervalSeconds < 1 {
+		return fmt.Errorf("runner_heartbeat_interval_seconds must be at least 1")
+	}
+
+	if c.QueueBackend != "" && c.QueueBackend != "asynq" {
+		return fmt.Errorf("queue_backend must be '' or 'asynq'")
+	}
+
+	if c.QueueBackend == "asynq" && c.QueueRedisAddr == "" {
+		return fmt.Errorf("queue_redis_addr is required when queue_backend is 'asynq'")
+	}
+
+	if c.MetricsNamespace == "" || c.MetricsSubsystem == "" {
+		return fmt.Errorf("metrics_namespace and metrics_subsystem must not be empty")
+	}
+
+	if c.AuthJWTSecret == "" {
+		return fmt.Errorf("auth_jwt_secret is required")
+	}
+
+	if c.AuthJWTExpirySeconds < 1 {
+		return fmt.Errorf("auth_jwt_expiry_seconds must be at least 1")
+	}
+
+	if c.AuthLoginRateLimitRPM < 1 {
+		return fmt.Errorf("auth_login_rate_limit_rpm must be at least 1")
+	}
+
+	if c.PackageIndexCheckIntervalSeconds < 1 {
+		return fmt.Errorf("package_index_check_interval_seconds must be at least 1")
+	}
+
 	return nil
 }
@@ -0,0 +1,383 @@
+package container
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/events"
+	"github.com/docker/docker/api/types/filters"
+	"github.com/docker/docker/api/types/mount"
+	"github.com/docker/docker/client"
+	"github.com/docker/docker/pkg/stdcopy"
+
+	"github.com/aparcar/asu/builder/internal/channel"
+)
+
+// DockerManager handles container operations using the Docker Engine API.
+// It implements the same Runtime interface as PodmanManager so the builder
+// package can run ImageBuilder containers on either engine.
+type DockerManager struct {
+	cli *client.Client
+}
+
+// NewDockerManager creates a DockerManager from the standard
+// DOCKER_HOST/DOCKER_TLS_VERIFY/DOCKER_CERT_PATH environment variables.
+func NewDockerManager() (*DockerManager, error) {
+	cli, err := client.NewClientWithOpts(client.FromEnv, client.WithAPIVersionNegotiation())
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Docker client: %w", err)
+	}
+
+	return &DockerManager{cli: cli}, nil
+}
+
+// RunContainer runs a container and waits for it to complete.
+func (m *DockerManager) RunContainer(opts ContainerRunOptions) (string, error) {
+	ctx := opts.Ctx
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	exists, err := m.ImageExists(opts.Image)
+	if err != nil {
+		return "", err
+	}
+	if !exists {
+		if err := m.PullImage(opts.Image); err != nil {
+			return "", err
+		}
+	}
+
+	env := make([]string, 0, len(opts.Environment))
+	for k, v := range opts.Environment {
+		env = append(env, fmt.Sprintf("%s=%s", k, v))
+	}
+
+	mounts := make([]mount.Mount, 0, len(opts.Mounts))
+	for _, mnt := range opts.Mounts {
+		mounts = append(mounts, mount.Mount{
+			Type:     mount.TypeBind,
+			Source:   mnt.Source,
+			Target:   mnt.Target,
+			ReadOnly: mnt.ReadOnly,
+		})
+	}
+
+	resp, err := m.cli.ContainerCreate(ctx, &container.Config{
+		Image:      opts.Image,
+		Cmd:        opts.Command,
+		Env:        env,
+		WorkingDir: opts.WorkDir,
+		Labels:     opts.Labels,
+	}, &container.HostConfig{
+		Mounts:     mounts,
+		AutoRemove: opts.Remove,
+	}, nil, nil, opts.Name)
+	if err != nil {
+		return "", fmt.Errorf("failed to create container: %w", err)
+	}
+	containerID := resp.ID
+
+	if err := m.cli.ContainerStart(ctx, containerID, types.ContainerStartOptions{}); err != nil {
+		return "", fmt.Errorf("failed to start container: %w", err)
+	}
+
+	if opts.OnContainerStart != nil {
+		opts.OnContainerStart(containerID)
+	}
+
+	// Tear the container down if opts.Ctx is cancelled before it finishes
+	// on its own. done is closed once the container exits so this doesn't
+	// leak a goroutine for the common case of a build that completes
+	// normally.
+	done := make(chan struct{})
+	defer close(done)
+	if opts.Ctx != nil {
+		go func() {
+			select {
+			case <-opts.Ctx.Done():
+				if err := m.Stop(containerID, containerStopTimeout); err != nil {
+					log.Printf("failed to stop cancelled container %s: %v", containerID, err)
+				}
+				if err := m.RemoveContainer(containerID); err != nil {
+					log.Printf("failed to remove cancelled container %s: %v", containerID, err)
+				}
+			case <-done:
+			}
+		}()
+	}
+
+	// Fan live lines out to opts.LogWriter, if given, through a bounded
+	// channel so a slow consumer can never stall this read loop.
+	var live *channel.Writer
+	var wg sync.WaitGroup
+	if opts.LogWriter != nil {
+		live = channel.New(256)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for frame := range live.Frames() {
+				opts.LogWriter.Write(frame)
+			}
+		}()
+	}
+
+	logs, err := m.cli.ContainerLogs(ctx, containerID, types.ContainerLogsOptions{
+		ShowStdout: true,
+		ShowStderr: true,
+		Follow:     true,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to get container logs: %w", err)
+	}
+	defer logs.Close()
+
+	// Containers created without a TTY have their stdout/stderr multiplexed
+	// into the Docker log stream as [8-byte header][payload] frames;
+	// stdcopy.StdCopy demultiplexes them back into plain bytes.
+	var output bytes.Buffer
+	dst := io.Writer(&output)
+	if live != nil {
+		dst = io.MultiWriter(&output, live)
+	}
+	if _, err := stdcopy.StdCopy(dst, dst, logs); err != nil && err != io.EOF {
+		return "", fmt.Errorf("failed to read container logs: %w", err)
+	}
+	if live != nil {
+		live.Close()
+		wg.Wait()
+	}
+
+	statusCh, errCh := m.cli.ContainerWait(ctx, containerID, container.WaitConditionNotRunning)
+	select {
+	case err := <-errCh:
+		if err != nil {
+			return output.String(), fmt.Errorf("container execution failed: %w", err)
+		}
+	case status := <-statusCh:
+		if status.StatusCode != 0 {
+			return output.String(), fmt.Errorf("container exited with code %d", status.StatusCode)
+		}
+	}
+
+	return output.String(), nil
+}
+
+// PullImage pulls a container image, discarding progress.
+func (m *DockerManager) PullImage(image string) error {
+	return m.PullImageWithProgress(image, nil)
+}
+
+// PullImageWithProgress pulls image, decoding the Docker Engine's
+// newline-delimited JSON progress stream and invoking onProgress for each
+// line.
+func (m *DockerManager) PullImageWithProgress(image string, onProgress func(PullProgress)) error {
+	reader, err := m.cli.ImagePull(context.Background(), image, types.ImagePullOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to pull image: %w", err)
+	}
+	defer reader.Close()
+
+	dec := json.NewDecoder(reader)
+	for {
+		var line struct {
+			ID             string `json:"id"`
+			Status         string `json:"status"`
+			ProgressDetail struct {
+				Current int64 `json:"current"`
+				Total   int64 `json:"total"`
+			} `json:"progressDetail"`
+		}
+		if err := dec.Decode(&line); err != nil {
+			if err == io.EOF {
+				return nil
+			}
+			return fmt.Errorf("failed to decode pull progress: %w", err)
+		}
+		if onProgress != nil {
+			onProgress(PullProgress{
+				ID:      line.ID,
+				Status:  line.Status,
+				Current: line.ProgressDetail.Current,
+				Total:   line.ProgressDetail.Total,
+			})
+		}
+	}
+}
+
+// ImageExists checks if an image exists locally.
+func (m *DockerManager) ImageExists(image string) (bool, error) {
+	_, _, err := m.cli.ImageInspectWithRaw(context.Background(), image)
+	if err != nil {
+		if client.IsErrNotFound(err) {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to check image existence: %w", err)
+	}
+	return true, nil
+}
+
+// RemoveImage removes image from local storage, for the periodic
+// ImageBuilder GC (see container.Prewarmer.Prune).
+func (m *DockerManager) RemoveImage(image string) error {
+	if _, err := m.cli.ImageRemove(context.Background(), image, types.ImageRemoveOptions{}); err != nil {
+		return fmt.Errorf("failed to remove image %s: %w", image, err)
+	}
+	return nil
+}
+
+// Stop stops a running container.
+func (m *DockerManager) Stop(containerID string, timeout time.Duration) error {
+	seconds := int(timeout.Seconds())
+	if err := m.cli.ContainerStop(context.Background(), containerID, container.StopOptions{Timeout: &seconds}); err != nil {
+		return fmt.Errorf("failed to stop container: %w", err)
+	}
+	return nil
+}
+
+// RemoveContainer removes a container, forcing removal of one that's still
+// running (e.g. one whose Stop timed out).
+func (m *DockerManager) RemoveContainer(containerID string) error {
+	if err := m.cli.ContainerRemove(context.Background(), containerID, types.ContainerRemoveOptions{Force: true}); err != nil {
+		return fmt.Errorf("failed to remove container: %w", err)
+	}
+	return nil
+}
+
+// CopyFrom copies a file from a container to the host.
+func (m *DockerManager) CopyFrom(containerID, srcPath, dstPath string) error {
+	reader, _, err := m.cli.CopyFromContainer(context.Background(), containerID, srcPath)
+	if err != nil {
+		return fmt.Errorf("failed to copy from container: %w", err)
+	}
+	defer reader.Close()
+
+	if err := os.MkdirAll(filepath.Dir(dstPath), 0755); err != nil {
+		return fmt.Errorf("failed to create destination directory: %w", err)
+	}
+
+	out, err := os.Create(dstPath)
+	if err != nil {
+		return fmt.Errorf("failed to create destination file: %w", err)
+	}
+	defer out.Close()
+
+	if _, err := io.Copy(out, reader); err != nil {
+		return fmt.Errorf("failed to write file: %w", err)
+	}
+
+	return nil
+}
+
+// CopyTo copies a file from the host into a container.
+func (m *DockerManager) CopyTo(containerID, srcPath, dstPath string) error {
+	in, err := os.Open(srcPath)
+	if err != nil {
+		return fmt.Errorf("failed to open source file: %w", err)
+	}
+	defer in.Close()
+
+	if err := m.cli.CopyToContainer(context.Background(), containerID, dstPath, in, types.CopyToContainerOptions{}); err != nil {
+		return fmt.Errorf("failed to copy to container: %w", err)
+	}
+	return nil
+}
+
+// Stats returns a single resource usage sample for containerID.
+func (m *DockerManager) Stats(containerID string) (ContainerStats, error) {
+	resp, err := m.cli.ContainerStats(context.Background(), containerID, false)
+	if err != nil {
+		return ContainerStats{}, fmt.Errorf("failed to get container stats: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var stats types.StatsJSON
+	if err := json.NewDecoder(resp.Body).Decode(&stats); err != nil {
+		return ContainerStats{}, fmt.Errorf("failed to decode container stats: %w", err)
+	}
+
+	cpuDelta := float64(stats.CPUStats.CPUUsage.TotalUsage - stats.PreCPUStats.CPUUsage.TotalUsage)
+	systemDelta := float64(stats.CPUStats.SystemUsage - stats.PreCPUStats.SystemUsage)
+	var cpuPercent float64
+	if systemDelta > 0 && cpuDelta > 0 {
+		cpuPercent = (cpuDelta / systemDelta) * float64(len(stats.CPUStats.CPUUsage.PercpuUsage)) * 100
+	}
+
+	var blockRead, blockWrite uint64
+	for _, entry := range stats.BlkioStats.IoServiceBytesRecursive {
+		switch strings.ToLower(entry.Op) {
+		case "read":
+			blockRead += entry.Value
+		case "write":
+			blockWrite += entry.Value
+		}
+	}
+
+	var netRx, netTx uint64
+	for _, net := range stats.Networks {
+		netRx += net.RxBytes
+		netTx += net.TxBytes
+	}
+
+	return ContainerStats{
+		CPUPercent:      cpuPercent,
+		MemUsageBytes:   stats.MemoryStats.Usage,
+		MemLimitBytes:   stats.MemoryStats.Limit,
+		BlockReadBytes:  blockRead,
+		BlockWriteBytes: blockWrite,
+		NetRxBytes:      netRx,
+		NetTxBytes:      netTx,
+	}, nil
+}
+
+// Events streams container lifecycle events until ctx is cancelled.
+func (m *DockerManager) Events(ctx context.Context) (<-chan Event, error) {
+	dockerEvents, errs := m.cli.Events(ctx, types.EventsOptions{
+		Filters: filters.NewArgs(filters.Arg("type", string(events.ContainerEventType))),
+	})
+
+	out := make(chan Event)
+	go func() {
+		defer close(out)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case err := <-errs:
+				if err != nil {
+					return
+				}
+			case ev, ok := <-dockerEvents:
+				if !ok {
+					return
+				}
+				out <- Event{
+					Type:        string(ev.Type),
+					Status:      string(ev.Action),
+					ContainerID: ev.Actor.ID,
+					Time:        time.Unix(0, ev.TimeNano),
+					Labels:      ev.Actor.Attributes,
+				}
+			}
+		}
+	}()
+
+	return out, nil
+}
+
+// SupportsBindMounts always returns true: Docker always has direct
+// filesystem access to bind-mount a host path.
+func (m *DockerManager) SupportsBindMounts() bool {
+	return true
+}
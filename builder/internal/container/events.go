@@ -0,0 +1,50 @@
+package container
+
+import "context"
+
+// BuildHashLabel is the container label a build's container is tagged with,
+// so EventStream can pick its events out of the runtime's shared event
+// feed. See also BuildVersionLabel, BuildTargetLabel, and BuildProfileLabel.
+const (
+	BuildHashLabel    = "asu.build.hash"
+	BuildVersionLabel = "asu.build.version"
+	BuildTargetLabel  = "asu.build.target"
+	BuildProfileLabel = "asu.build.profile"
+)
+
+// EventStream filters a Runtime's shared lifecycle event feed down to the
+// events for a single build's container, identified by its BuildHashLabel.
+type EventStream struct {
+	runtime Runtime
+}
+
+// NewEventStream creates an EventStream backed by runtime.
+func NewEventStream(runtime Runtime) *EventStream {
+	return &EventStream{runtime: runtime}
+}
+
+// Watch subscribes to the runtime's event feed and forwards only events
+// whose BuildHashLabel matches requestHash, until ctx is cancelled.
+func (s *EventStream) Watch(ctx context.Context, requestHash string) (<-chan Event, error) {
+	events, err := s.runtime.Events(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make(chan Event)
+	go func() {
+		defer close(out)
+		for ev := range events {
+			if ev.Labels[BuildHashLabel] != requestHash {
+				continue
+			}
+			select {
+			case out <- ev:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return out, nil
+}
@@ -1,34 +1,69 @@
 package container
 
 import (
+	"archive/tar"
 	"context"
 	"fmt"
 	"io"
+	"log"
+	"net/url"
 	"os"
 	"path/filepath"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/containers/podman/v4/pkg/bindings"
 	"github.com/containers/podman/v4/pkg/bindings/containers"
 	"github.com/containers/podman/v4/pkg/bindings/images"
+	"github.com/containers/podman/v4/pkg/bindings/system"
+	"github.com/containers/podman/v4/pkg/domain/entities"
 	"github.com/containers/podman/v4/pkg/specgen"
+	ocispec "github.com/opencontainers/runtime-spec/specs-go"
+
+	"github.com/aparcar/asu/builder/internal/channel"
 )
 
 // PodmanManager handles container operations using Podman bindings
 type PodmanManager struct {
 	ctx context.Context
+	// remote is true when this manager's socket isn't reachable through a
+	// local bind mount (a tcp:// or non-local unix:// socket, e.g. a
+	// Podman-in-Kubernetes pod or a rootless Podman farm), in which case
+	// RunContainer streams ContainerRunOptions.Mounts in and out as tar
+	// archives instead of bind-mounting them.
+	remote bool
 }
 
-// NewPodmanManager creates a new Podman manager
-func NewPodmanManager(socketPath string) (*PodmanManager, error) {
-	// Connect to Podman socket
-	connText := fmt.Sprintf("unix://%s", socketPath)
+// NewPodmanManager creates a new Podman manager. socket is either a bare
+// filesystem path to a local Podman socket (the common case, assumed
+// unix://) or a full connection URI (e.g. tcp://host:2376 or
+// unix://remote-host/run/podman/podman.sock) for a remote socket.
+func NewPodmanManager(socket string) (*PodmanManager, error) {
+	connText := socket
+	if !strings.Contains(connText, "://") {
+		connText = fmt.Sprintf("unix://%s", connText)
+	}
+
 	ctx, err := bindings.NewConnection(context.Background(), connText)
 	if err != nil {
 		return nil, fmt.Errorf("failed to connect to Podman: %w", err)
 	}
 
-	return &PodmanManager{ctx: ctx}, nil
+	return &PodmanManager{ctx: ctx, remote: isRemoteSocket(connText)}, nil
+}
+
+// isRemoteSocket reports whether connText points at a Podman socket that
+// has no access to this host's filesystem.
+func isRemoteSocket(connText string) bool {
+	u, err := url.Parse(connText)
+	if err != nil {
+		return false
+	}
+	if u.Scheme != "unix" {
+		return true
+	}
+	return u.Host != "" && u.Host != "localhost"
 }
 
 // ContainerRunOptions holds options for running a container
@@ -40,13 +75,58 @@ type ContainerRunOptions struct {
 	WorkDir     string
 	Command     []string
 	Remove      bool
+	// Labels are attached to the container and carried through to its
+	// lifecycle events, letting EventStream pick this container's events
+	// out of the runtime's shared event feed.
+	Labels map[string]string
+	// LogWriter, if set, receives the container's combined stdout/stderr as
+	// it's produced instead of only once RunContainer returns. Writes to it
+	// are fanned through a channel.Writer so a slow LogWriter can never
+	// stall the underlying log read loop.
+	LogWriter io.Writer
+	// OnContainerStart, if set, is called with the container's ID as soon
+	// as it starts, before RunContainer blocks reading its logs to
+	// completion. Used to start a StatsCollector against the right
+	// container.
+	OnContainerStart func(containerID string)
+	// Ctx, if set, is watched for the lifetime of the container. When it's
+	// cancelled (client disconnect, JobTimeoutSeconds exceeded, or an
+	// explicit user cancellation), RunContainer stops and removes the
+	// container instead of leaving it to run to completion.
+	Ctx context.Context
 }
 
+// containerStopTimeout is how long a container is given to exit cleanly
+// after a stop signal before the runtime kills it outright.
+const containerStopTimeout = 10 * time.Second
+
+// MountMode selects how a Mount's Source/Target are applied to the
+// container.
+type MountMode string
+
+const (
+	// ModeBind bind-mounts Source directly into the container. This is the
+	// default, and the only mode Docker and a local Podman socket need.
+	ModeBind MountMode = "bind"
+	// ModeCopyIn streams Source into the container at Target as a tar
+	// archive before it starts, for a PodmanManager with no access to this
+	// host's filesystem (see PodmanManager.remote).
+	ModeCopyIn MountMode = "copy_in"
+	// ModeCopyOut streams Target out of the container into Source as a tar
+	// archive once it exits, for a PodmanManager with no access to this
+	// host's filesystem (see PodmanManager.remote).
+	ModeCopyOut MountMode = "copy_out"
+)
+
 // Mount represents a volume mount
 type Mount struct {
 	Source   string
 	Target   string
 	ReadOnly bool
+	// Mode is ignored by Docker and a local Podman socket, which always
+	// bind-mount. A remote Podman socket requires every Mount to set this
+	// to ModeCopyIn or ModeCopyOut, since it has nothing to bind-mount.
+	Mode MountMode
 }
 
 // RunContainer runs a container and waits for it to complete
@@ -63,11 +143,12 @@ func (m *PodmanManager) RunContainer(opts ContainerRunOptions) (string, error) {
 	}
 
 	// Create container spec
+	autoRemove := opts.Remove
 	spec := &specgen.SpecGenerator{
 		ContainerBasicConfig: specgen.ContainerBasicConfig{
 			Name:    opts.Name,
-			Remove:  &opts.Remove,
 			Command: opts.Command,
+			Labels:  opts.Labels,
 		},
 		ContainerStorageConfig: specgen.ContainerStorageConfig{
 			Image: opts.Image,
@@ -88,22 +169,42 @@ func (m *PodmanManager) RunContainer(opts ContainerRunOptions) (string, error) {
 		spec.Env = env
 	}
 
-	// Add mounts
-	if len(opts.Mounts) > 0 {
-		mounts := []specgen.Mount{}
+	// Add mounts. A remote socket can't bind-mount a host path, so its
+	// Mounts are staged in/out as tar archives instead, around Start/Wait
+	// below.
+	var copyInMounts, copyOutMounts []Mount
+	if m.remote {
+		for _, mnt := range opts.Mounts {
+			switch mnt.Mode {
+			case ModeCopyIn:
+				copyInMounts = append(copyInMounts, mnt)
+			case ModeCopyOut:
+				copyOutMounts = append(copyOutMounts, mnt)
+			default:
+				return "", fmt.Errorf("podman: remote socket can't bind-mount %s, set Mode to ModeCopyIn or ModeCopyOut", mnt.Source)
+			}
+		}
+		if len(copyOutMounts) > 0 {
+			// We need to copy the artifacts out ourselves once the
+			// container exits, before it disappears.
+			autoRemove = false
+		}
+	} else if len(opts.Mounts) > 0 {
+		mounts := []ocispec.Mount{}
 		for _, mount := range opts.Mounts {
-			m := specgen.Mount{
+			mt := ocispec.Mount{
 				Source:      mount.Source,
 				Destination: mount.Target,
 				Type:        "bind",
 			}
 			if mount.ReadOnly {
-				m.Options = []string{"ro"}
+				mt.Options = []string{"ro"}
 			}
-			mounts = append(mounts, m)
+			mounts = append(mounts, mt)
 		}
 		spec.Mounts = mounts
 	}
+	spec.Remove = autoRemove
 
 	// Create container
 	createResponse, err := containers.CreateWithSpec(m.ctx, spec, nil)
@@ -113,11 +214,42 @@ func (m *PodmanManager) RunContainer(opts ContainerRunOptions) (string, error) {
 
 	containerID := createResponse.ID
 
+	for _, mnt := range copyInMounts {
+		if err := m.copyDirToContainer(containerID, mnt.Source, mnt.Target); err != nil {
+			return "", fmt.Errorf("failed to stage %s into container: %w", mnt.Source, err)
+		}
+	}
+
 	// Start container
 	if err := containers.Start(m.ctx, containerID, nil); err != nil {
 		return "", fmt.Errorf("failed to start container: %w", err)
 	}
 
+	if opts.OnContainerStart != nil {
+		opts.OnContainerStart(containerID)
+	}
+
+	// Tear the container down if opts.Ctx is cancelled before it finishes
+	// on its own. done is closed once the container exits so this doesn't
+	// leak a goroutine for the common case of a build that completes
+	// normally.
+	done := make(chan struct{})
+	defer close(done)
+	if opts.Ctx != nil {
+		go func() {
+			select {
+			case <-opts.Ctx.Done():
+				if err := m.Stop(containerID, containerStopTimeout); err != nil {
+					log.Printf("failed to stop cancelled container %s: %v", containerID, err)
+				}
+				if err := m.RemoveContainer(containerID); err != nil {
+					log.Printf("failed to remove cancelled container %s: %v", containerID, err)
+				}
+			case <-done:
+			}
+		}()
+	}
+
 	// Wait for container to finish
 	waitChan := make(chan error)
 	go func() {
@@ -125,37 +257,107 @@ func (m *PodmanManager) RunContainer(opts ContainerRunOptions) (string, error) {
 		waitChan <- err
 	}()
 
-	// Get logs
-	logOptions := &containers.LogOptions{
-		Stdout: bindings.PTrue,
-		Stderr: bindings.PTrue,
-		Follow: bindings.PTrue,
-	}
+	// Get logs. The bindings hand stdout/stderr back on two separate
+	// channels that Logs writes to directly (and never closes itself), so
+	// they're merged into the single logChan the rest of this function
+	// expects, closing both only once Logs has returned and will no longer
+	// write to them.
+	logOptions := new(containers.LogOptions).WithStdout(true).WithStderr(true).WithFollow(true)
 
-	logChan, err := containers.Logs(m.ctx, containerID, logOptions)
-	if err != nil {
-		return "", fmt.Errorf("failed to get container logs: %w", err)
+	stdoutChan := make(chan string)
+	stderrChan := make(chan string)
+	logErrChan := make(chan error, 1)
+	go func() {
+		err := containers.Logs(m.ctx, containerID, logOptions, stdoutChan, stderrChan)
+		close(stdoutChan)
+		close(stderrChan)
+		logErrChan <- err
+	}()
+
+	logChan := make(chan string)
+	go func() {
+		defer close(logChan)
+		stdout, stderr := stdoutChan, stderrChan
+		for stdout != nil || stderr != nil {
+			select {
+			case line, ok := <-stdout:
+				if !ok {
+					stdout = nil
+					continue
+				}
+				logChan <- line
+			case line, ok := <-stderr:
+				if !ok {
+					stderr = nil
+					continue
+				}
+				logChan <- line
+			}
+		}
+	}()
+
+	// Fan live lines out to opts.LogWriter, if given, through a bounded
+	// channel so a slow consumer (e.g. a worker POSTing lines to the API
+	// server) can never stall this read loop.
+	var live *channel.Writer
+	var wg sync.WaitGroup
+	if opts.LogWriter != nil {
+		live = channel.New(256)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for frame := range live.Frames() {
+				opts.LogWriter.Write(frame)
+			}
+		}()
 	}
 
 	// Collect logs
 	var output strings.Builder
 	for line := range logChan {
 		output.WriteString(line)
+		if live != nil {
+			live.Write([]byte(line))
+		}
+	}
+	if live != nil {
+		live.Close()
+		wg.Wait()
+	}
+	if err := <-logErrChan; err != nil {
+		log.Printf("log stream for container %s ended with error: %v", containerID, err)
 	}
 
 	// Wait for container to finish
-	if err := <-waitChan; err != nil {
-		return output.String(), fmt.Errorf("container execution failed: %w", err)
+	waitErr := <-waitChan
+
+	for _, mnt := range copyOutMounts {
+		if err := m.copyDirFromContainer(containerID, mnt.Target, mnt.Source); err != nil {
+			log.Printf("failed to fetch %s from container %s: %v", mnt.Target, containerID, err)
+		}
 	}
 
-	// Check exit code
+	// Check exit code, while the container (which we may have disabled
+	// auto-remove on above) still exists to inspect.
+	var exitErr error
 	inspectData, err := containers.Inspect(m.ctx, containerID, nil)
 	if err != nil {
-		return output.String(), fmt.Errorf("failed to inspect container: %w", err)
+		exitErr = fmt.Errorf("failed to inspect container: %w", err)
+	} else if inspectData.State.ExitCode != 0 {
+		exitErr = fmt.Errorf("container exited with code %d", inspectData.State.ExitCode)
+	}
+
+	if !autoRemove && len(copyOutMounts) > 0 {
+		if err := m.RemoveContainer(containerID); err != nil {
+			log.Printf("failed to remove container %s after copying artifacts out: %v", containerID, err)
+		}
 	}
 
-	if inspectData.State.ExitCode != 0 {
-		return output.String(), fmt.Errorf("container exited with code %d", inspectData.State.ExitCode)
+	if waitErr != nil {
+		return output.String(), fmt.Errorf("container execution failed: %w", waitErr)
+	}
+	if exitErr != nil {
+		return output.String(), exitErr
 	}
 
 	return output.String(), nil
@@ -163,6 +365,18 @@ func (m *PodmanManager) RunContainer(opts ContainerRunOptions) (string, error) {
 
 // PullImage pulls a container image
 func (m *PodmanManager) PullImage(image string) error {
+	return m.PullImageWithProgress(image, nil)
+}
+
+// PullImageWithProgress pulls image. The Podman v4 bindings don't expose
+// libpod's per-layer pull progress (that's only surfaced to the CLI), so
+// onProgress just gets a single "pulling" update rather than per-layer
+// detail.
+func (m *PodmanManager) PullImageWithProgress(image string, onProgress func(PullProgress)) error {
+	if onProgress != nil {
+		onProgress(PullProgress{ID: image, Status: "pulling"})
+	}
+
 	_, err := images.Pull(m.ctx, image, nil)
 	if err != nil {
 		return fmt.Errorf("failed to pull image: %w", err)
@@ -179,13 +393,31 @@ func (m *PodmanManager) ImageExists(image string) (bool, error) {
 	return exists, nil
 }
 
-// CopyFromContainer copies files from container to host
-func (m *PodmanManager) CopyFromContainer(containerID, srcPath, dstPath string) error {
-	reader, _, err := containers.CopyFromArchive(m.ctx, containerID, srcPath, nil)
+// RemoveImage removes image from local storage, for the periodic
+// ImageBuilder GC (see container.Prewarmer.Prune).
+func (m *PodmanManager) RemoveImage(image string) error {
+	_, errs := images.Remove(m.ctx, []string{image}, nil)
+	if len(errs) > 0 {
+		return fmt.Errorf("failed to remove image %s: %w", image, errs[0])
+	}
+	return nil
+}
+
+// CopyFrom copies a single file from a container to the host. Despite the
+// name, the bindings' CopyToArchive is the GET side of the archive API (it
+// streams the remote path's contents into a writer) — CopyFromArchive is the
+// PUT side, for pushing a local archive into the container (see CopyTo).
+func (m *PodmanManager) CopyFrom(containerID, srcPath, dstPath string) error {
+	pr, pw := io.Pipe()
+
+	copyFunc, err := containers.CopyToArchive(m.ctx, containerID, srcPath, pw)
 	if err != nil {
+		pw.Close()
 		return fmt.Errorf("failed to copy from container: %w", err)
 	}
-	defer reader.Close()
+	go func() {
+		pw.CloseWithError(copyFunc())
+	}()
 
 	// Create destination directory
 	if err := os.MkdirAll(filepath.Dir(dstPath), 0755); err != nil {
@@ -199,19 +431,306 @@ func (m *PodmanManager) CopyFromContainer(containerID, srcPath, dstPath string)
 	}
 	defer out.Close()
 
-	if _, err := io.Copy(out, reader); err != nil {
+	if err := untarSingleFileTo(out, pr); err != nil {
 		return fmt.Errorf("failed to write file: %w", err)
 	}
 
 	return nil
 }
 
-// RemoveContainer removes a container
+// CopyTo copies a file from the host into a container. The archive API only
+// accepts tar streams, so srcPath is wrapped in a single-entry tar before
+// being PUT via CopyFromArchive (see CopyFrom's doc comment on the naming).
+func (m *PodmanManager) CopyTo(containerID, srcPath, dstPath string) error {
+	in, err := os.Open(srcPath)
+	if err != nil {
+		return fmt.Errorf("failed to open source file: %w", err)
+	}
+	defer in.Close()
+
+	info, err := in.Stat()
+	if err != nil {
+		return fmt.Errorf("failed to stat source file: %w", err)
+	}
+
+	tarStream, err := tarSingleFile(filepath.Base(dstPath), info, in)
+	if err != nil {
+		return fmt.Errorf("failed to archive source file: %w", err)
+	}
+
+	copyFunc, err := containers.CopyFromArchive(m.ctx, containerID, filepath.Dir(dstPath), tarStream)
+	if err != nil {
+		return fmt.Errorf("failed to copy to container: %w", err)
+	}
+	if err := copyFunc(); err != nil {
+		return fmt.Errorf("failed to copy to container: %w", err)
+	}
+
+	return nil
+}
+
+// copyDirToContainer archives hostDir's contents and streams them into
+// containerDir inside the container, for a remote socket's ModeCopyIn
+// mounts.
+func (m *PodmanManager) copyDirToContainer(containerID, hostDir, containerDir string) error {
+	tarStream, err := tarDirectory(hostDir)
+	if err != nil {
+		return fmt.Errorf("failed to archive %s: %w", hostDir, err)
+	}
+
+	copyFunc, err := containers.CopyFromArchive(m.ctx, containerID, containerDir, tarStream)
+	if err != nil {
+		return fmt.Errorf("failed to stage archive into container: %w", err)
+	}
+	if err := copyFunc(); err != nil {
+		return fmt.Errorf("failed to stage archive into container: %w", err)
+	}
+
+	return nil
+}
+
+// copyDirFromContainer fetches containerDir out of the container as a tar
+// archive and extracts it into hostDir, for a remote socket's ModeCopyOut
+// mounts.
+func (m *PodmanManager) copyDirFromContainer(containerID, containerDir, hostDir string) error {
+	pr, pw := io.Pipe()
+
+	copyFunc, err := containers.CopyToArchive(m.ctx, containerID, containerDir, pw)
+	if err != nil {
+		pw.Close()
+		return fmt.Errorf("failed to fetch archive from container: %w", err)
+	}
+	go func() {
+		pw.CloseWithError(copyFunc())
+	}()
+
+	if err := os.MkdirAll(hostDir, 0755); err != nil {
+		return fmt.Errorf("failed to create %s: %w", hostDir, err)
+	}
+
+	return untarTo(hostDir, pr)
+}
+
+// tarDirectory archives dir's contents (not dir itself) into a tar stream
+// suitable for containers.CopyFromArchive.
+func tarDirectory(dir string) (io.Reader, error) {
+	pr, pw := io.Pipe()
+
+	go func() {
+		tw := tar.NewWriter(pw)
+		walkErr := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+			rel, err := filepath.Rel(dir, path)
+			if err != nil {
+				return err
+			}
+			if rel == "." {
+				return nil
+			}
+
+			hdr, err := tar.FileInfoHeader(info, "")
+			if err != nil {
+				return err
+			}
+			hdr.Name = rel
+			if err := tw.WriteHeader(hdr); err != nil {
+				return err
+			}
+			if info.IsDir() {
+				return nil
+			}
+
+			f, err := os.Open(path)
+			if err != nil {
+				return err
+			}
+			defer f.Close()
+			_, err = io.Copy(tw, f)
+			return err
+		})
+		if walkErr == nil {
+			walkErr = tw.Close()
+		}
+		pw.CloseWithError(walkErr)
+	}()
+
+	return pr, nil
+}
+
+// untarTo extracts a tar stream (as returned by containers.CopyToArchive
+// for a directory path) into dir. Podman roots such an archive at the
+// copied directory's own basename (e.g. fetching /builder/bin yields
+// entries under "bin/"), so the leading path component is stripped to land
+// files directly under dir.
+func untarTo(dir string, r io.Reader) error {
+	tr := tar.NewReader(r)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read archive: %w", err)
+		}
+
+		name := hdr.Name
+		if idx := strings.IndexByte(name, '/'); idx >= 0 {
+			name = name[idx+1:]
+		} else {
+			continue
+		}
+		if name == "" {
+			continue
+		}
+		target := filepath.Join(dir, name)
+
+		switch hdr.Typeflag {
+		case tar.TypeDir:
+			if err := os.MkdirAll(target, 0755); err != nil {
+				return err
+			}
+		case tar.TypeReg:
+			if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+				return err
+			}
+			f, err := os.OpenFile(target, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, os.FileMode(hdr.Mode))
+			if err != nil {
+				return err
+			}
+			if _, err := io.Copy(f, tr); err != nil {
+				f.Close()
+				return err
+			}
+			f.Close()
+		}
+	}
+}
+
+// tarSingleFile wraps r in a tar stream containing one regular file entry
+// named name, for CopyTo's CopyFromArchive call — the archive API only
+// accepts tar payloads, even for a single file.
+func tarSingleFile(name string, info os.FileInfo, r io.Reader) (io.Reader, error) {
+	pr, pw := io.Pipe()
+
+	go func() {
+		tw := tar.NewWriter(pw)
+		hdr, err := tar.FileInfoHeader(info, "")
+		if err == nil {
+			hdr.Name = name
+			err = tw.WriteHeader(hdr)
+		}
+		if err == nil {
+			_, err = io.Copy(tw, r)
+		}
+		if err == nil {
+			err = tw.Close()
+		}
+		pw.CloseWithError(err)
+	}()
+
+	return pr, nil
+}
+
+// untarSingleFileTo copies the first regular file entry found in the tar
+// stream r (as returned by containers.CopyToArchive for a single-file path)
+// to w.
+func untarSingleFileTo(w io.Writer, r io.Reader) error {
+	tr := tar.NewReader(r)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return fmt.Errorf("archive contained no regular file")
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read archive: %w", err)
+		}
+		if hdr.Typeflag == tar.TypeReg {
+			_, err := io.Copy(w, tr)
+			return err
+		}
+	}
+}
+
+// Stop stops a running container.
+func (m *PodmanManager) Stop(containerID string, timeout time.Duration) error {
+	opts := new(containers.StopOptions).WithTimeout(uint(timeout.Seconds()))
+	if err := containers.Stop(m.ctx, containerID, opts); err != nil {
+		return fmt.Errorf("failed to stop container: %w", err)
+	}
+	return nil
+}
+
+// RemoveContainer removes a container, forcing removal of one that's still
+// running (e.g. one whose Stop timed out).
 func (m *PodmanManager) RemoveContainer(containerID string) error {
-	_, err := containers.Remove(m.ctx, containerID, nil)
+	_, err := containers.Remove(m.ctx, containerID, new(containers.RemoveOptions).WithForce(true))
 	return err
 }
 
+// Stats returns a single resource usage sample for containerID.
+func (m *PodmanManager) Stats(containerID string) (ContainerStats, error) {
+	statsChan, err := containers.Stats(m.ctx, []string{containerID}, nil)
+	if err != nil {
+		return ContainerStats{}, fmt.Errorf("failed to get container stats: %w", err)
+	}
+
+	report, ok := <-statsChan
+	if !ok || len(report.Stats) == 0 {
+		return ContainerStats{}, fmt.Errorf("no stats reported for container %s", containerID)
+	}
+
+	s := report.Stats[0]
+	return ContainerStats{
+		CPUPercent:      s.CPU,
+		MemUsageBytes:   s.MemUsage,
+		MemLimitBytes:   s.MemLimit,
+		BlockReadBytes:  s.BlockInput,
+		BlockWriteBytes: s.BlockOutput,
+		NetRxBytes:      s.NetInput,
+		NetTxBytes:      s.NetOutput,
+	}, nil
+}
+
+// Events streams container lifecycle events until ctx is cancelled.
+func (m *PodmanManager) Events(ctx context.Context) (<-chan Event, error) {
+	podmanEvents := make(chan entities.Event)
+	cancelChan := make(chan bool)
+	out := make(chan Event)
+
+	go func() {
+		<-ctx.Done()
+		close(cancelChan)
+	}()
+
+	errChan := make(chan error, 1)
+	go func() {
+		errChan <- system.Events(m.ctx, podmanEvents, cancelChan, new(system.EventsOptions))
+	}()
+
+	go func() {
+		defer close(out)
+		for ev := range podmanEvents {
+			out <- Event{
+				Type:        string(ev.Type),
+				Status:      string(ev.Status),
+				ContainerID: ev.Actor.ID,
+				Time:        time.Unix(ev.Time, 0),
+				Labels:      ev.Actor.Attributes,
+			}
+		}
+	}()
+
+	return out, nil
+}
+
+// SupportsBindMounts reports whether m can bind-mount a host path: true for
+// a local socket, false for a remote one (see m.remote).
+func (m *PodmanManager) SupportsBindMounts() bool {
+	return !m.remote
+}
+
 // GetImageBuilderTag returns the full image tag for an ImageBuilder
 func GetImageBuilderTag(registry, version, target string) string {
 	// Split target into target/subtarget
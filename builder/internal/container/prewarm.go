@@ -0,0 +1,318 @@
+package container
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+)
+
+// Image prewarm states, as reported by GET /api/v1/imagebuilders.
+const (
+	ImageStatusPending = "pending"
+	ImageStatusPulling = "pulling"
+	ImageStatusReady   = "ready"
+	ImageStatusError   = "error"
+)
+
+// ImageBuilderState is the prewarm status of a single ImageBuilder image.
+type ImageBuilderState struct {
+	Image      string                  `json:"image"`
+	Status     string                  `json:"status"`
+	Layers     map[string]PullProgress `json:"layers,omitempty"`
+	StartedAt  time.Time               `json:"started_at"`
+	FinishedAt time.Time               `json:"finished_at,omitempty"`
+	Error      string                  `json:"error,omitempty"`
+}
+
+// imageBuilderEntry tracks one image's in-flight or completed pull.
+type imageBuilderEntry struct {
+	mu    sync.Mutex
+	state ImageBuilderState
+	done  chan struct{}
+
+	// subscribers receive every PullProgress update as the pull runs, for
+	// PullWithOutput to relay into a build's log stream. Each gets its own
+	// bounded channel so one slow reader can't stall the pull or the
+	// others; a full channel just drops the update (see (*imageBuilderEntry).publish).
+	subscribers map[int]chan PullProgress
+	nextSubID   int
+}
+
+// Prewarmer pulls ImageBuilder images ahead of a build request, so a
+// worker's first request for a {version,target} doesn't silently block for
+// minutes inside Builder.Build. Its registry is queried directly by
+// GET /api/v1/imagebuilders and consulted by Build, which waits on an
+// in-flight pull's completion channel instead of triggering its own
+// redundant pull.
+type Prewarmer struct {
+	runtime Runtime
+
+	mu      sync.Mutex
+	entries map[string]*imageBuilderEntry
+}
+
+// NewPrewarmer creates a Prewarmer that pulls images through runtime.
+func NewPrewarmer(runtime Runtime) *Prewarmer {
+	return &Prewarmer{
+		runtime: runtime,
+		entries: make(map[string]*imageBuilderEntry),
+	}
+}
+
+// Warm starts pulling image in the background unless it's already pending,
+// pulling, or ready; a previously failed pull is retried. It returns
+// immediately; use Status or Wait to observe progress.
+func (p *Prewarmer) Warm(image string) {
+	p.getOrStartEntry(image)
+}
+
+// getOrStartEntry returns image's tracked entry, starting a new pull (and a
+// fresh entry) unless one is already pending, pulling, or ready. A
+// previously failed pull is retried. This is the dedup point shared by
+// Warm, Wait, and PullWithOutput: concurrent callers for the same image all
+// join the one entry a single pull() goroutine is filling in.
+func (p *Prewarmer) getOrStartEntry(image string) *imageBuilderEntry {
+	p.mu.Lock()
+	if existing, ok := p.entries[image]; ok {
+		existing.mu.Lock()
+		failed := existing.state.Status == ImageStatusError
+		existing.mu.Unlock()
+		if !failed {
+			p.mu.Unlock()
+			return existing
+		}
+	}
+	entry := &imageBuilderEntry{
+		state:       ImageBuilderState{Image: image, Status: ImageStatusPending, StartedAt: time.Now()},
+		done:        make(chan struct{}),
+		subscribers: make(map[int]chan PullProgress),
+	}
+	p.entries[image] = entry
+	p.mu.Unlock()
+
+	go p.pull(image, entry)
+	return entry
+}
+
+func (p *Prewarmer) pull(image string, entry *imageBuilderEntry) {
+	exists, err := p.runtime.ImageExists(image)
+	if err == nil && exists {
+		entry.mu.Lock()
+		entry.state.Status = ImageStatusReady
+		entry.state.FinishedAt = time.Now()
+		entry.mu.Unlock()
+		close(entry.done)
+		entry.closeSubscribers()
+		return
+	}
+
+	entry.mu.Lock()
+	entry.state.Status = ImageStatusPulling
+	entry.mu.Unlock()
+
+	err = p.runtime.PullImageWithProgress(image, func(pr PullProgress) {
+		entry.mu.Lock()
+		if entry.state.Layers == nil {
+			entry.state.Layers = make(map[string]PullProgress)
+		}
+		entry.state.Layers[pr.ID] = pr
+		entry.mu.Unlock()
+		entry.publish(pr)
+	})
+
+	entry.mu.Lock()
+	entry.state.FinishedAt = time.Now()
+	if err != nil {
+		entry.state.Status = ImageStatusError
+		entry.state.Error = err.Error()
+	} else {
+		entry.state.Status = ImageStatusReady
+	}
+	entry.mu.Unlock()
+	close(entry.done)
+	entry.closeSubscribers()
+}
+
+// Status returns the current state of image and whether it's tracked at
+// all; an image nobody has warmed or built yet isn't.
+func (p *Prewarmer) Status(image string) (ImageBuilderState, bool) {
+	p.mu.Lock()
+	entry, ok := p.entries[image]
+	p.mu.Unlock()
+	if !ok {
+		return ImageBuilderState{}, false
+	}
+
+	return entry.snapshot(), true
+}
+
+// List returns the state of every image the Prewarmer has warmed or been
+// asked to wait on, for GET /api/v1/imagebuilders.
+func (p *Prewarmer) List() []ImageBuilderState {
+	p.mu.Lock()
+	entries := make([]*imageBuilderEntry, 0, len(p.entries))
+	for _, e := range p.entries {
+		entries = append(entries, e)
+	}
+	p.mu.Unlock()
+
+	states := make([]ImageBuilderState, 0, len(entries))
+	for _, e := range entries {
+		states = append(states, e.snapshot())
+	}
+	return states
+}
+
+// snapshot returns a copy of the entry's state safe for a caller to read or
+// JSON-encode without holding entry.mu, including a copy of the Layers map
+// (which pull() keeps mutating in place until the pull finishes).
+func (e *imageBuilderEntry) snapshot() ImageBuilderState {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	state := e.state
+	if e.state.Layers != nil {
+		state.Layers = make(map[string]PullProgress, len(e.state.Layers))
+		for id, pr := range e.state.Layers {
+			state.Layers[id] = pr
+		}
+	}
+	return state
+}
+
+// subscribe registers a new progress subscriber, returning its channel and
+// an id to later unsubscribe with. If the pull has already finished, the
+// channel is returned already closed, matching the done-pull case a
+// PullWithOutput caller would otherwise see from entry.done.
+func (e *imageBuilderEntry) subscribe(bufSize int) (int, <-chan PullProgress) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	id := e.nextSubID
+	e.nextSubID++
+	ch := make(chan PullProgress, bufSize)
+	if e.state.Status == ImageStatusReady || e.state.Status == ImageStatusError {
+		close(ch)
+		return id, ch
+	}
+	e.subscribers[id] = ch
+	return id, ch
+}
+
+func (e *imageBuilderEntry) unsubscribe(id int) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if ch, ok := e.subscribers[id]; ok {
+		delete(e.subscribers, id)
+		close(ch)
+	}
+}
+
+// publish fans pr out to every current subscriber. A subscriber that isn't
+// keeping up has its update dropped rather than stalling the pull.
+func (e *imageBuilderEntry) publish(pr PullProgress) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	for _, ch := range e.subscribers {
+		select {
+		case ch <- pr:
+		default:
+		}
+	}
+}
+
+// closeSubscribers closes every subscriber channel, signalling end-of-stream
+// once the pull has finished.
+func (e *imageBuilderEntry) closeSubscribers() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	for id, ch := range e.subscribers {
+		close(ch)
+		delete(e.subscribers, id)
+	}
+}
+
+// Wait blocks until image's pull completes, starting one first if image
+// isn't already tracked. It returns ctx.Err() if ctx is cancelled first, or
+// an error describing the pull failure if the pull itself failed.
+func (p *Prewarmer) Wait(ctx context.Context, image string) error {
+	entry := p.getOrStartEntry(image)
+
+	select {
+	case <-entry.done:
+		entry.mu.Lock()
+		defer entry.mu.Unlock()
+		if entry.state.Status == ImageStatusError {
+			return fmt.Errorf("failed to pull %s: %s", image, entry.state.Error)
+		}
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// pullProgressSubscriberBuffer is how many progress updates a
+// PullWithOutput caller can lag behind the pull by before updates start
+// getting dropped (see (*imageBuilderEntry).publish).
+const pullProgressSubscriberBuffer = 32
+
+// PullWithOutput ensures image is pulled, deduping concurrent callers onto
+// the same in-flight pull (see getOrStartEntry), and writes a
+// human-readable line to output for every progress update along the way —
+// e.g. "Pulling openwrt/imagebuilder:23.05.3-ath79-generic: Downloading 42%" —
+// so a build's log stream shows pull progress before its container output
+// begins. onProgress, if non-nil, is additionally called with each raw
+// update (see builder.ProgressRecorder). It returns once the pull finishes
+// or ctx is cancelled.
+func (p *Prewarmer) PullWithOutput(ctx context.Context, image string, output io.Writer, onProgress func(PullProgress)) error {
+	entry := p.getOrStartEntry(image)
+	id, ch := entry.subscribe(pullProgressSubscriberBuffer)
+	defer entry.unsubscribe(id)
+
+	for {
+		select {
+		case pr, ok := <-ch:
+			if !ok {
+				entry.mu.Lock()
+				defer entry.mu.Unlock()
+				if entry.state.Status == ImageStatusError {
+					return fmt.Errorf("failed to pull %s: %s", image, entry.state.Error)
+				}
+				return nil
+			}
+			fmt.Fprintf(output, "Pulling %s: %s\n", image, formatPullProgress(pr))
+			if onProgress != nil {
+				onProgress(pr)
+			}
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// formatPullProgress renders pr for a build's log stream, falling back to a
+// bare status when the runtime couldn't report byte counts (e.g. Podman,
+// see PodmanManager.PullImageWithProgress).
+func formatPullProgress(pr PullProgress) string {
+	if pr.Total > 0 {
+		return fmt.Sprintf("%s %d%%", pr.Status, pr.Current*100/pr.Total)
+	}
+	return pr.Status
+}
+
+// Prune removes image from the container runtime and drops it from the
+// registry, for the periodic ImageBuilder GC (see api.StartImageGC). It is
+// a no-op in the registry if nobody has warmed or built image, but still
+// attempts the runtime removal.
+func (p *Prewarmer) Prune(image string) error {
+	p.mu.Lock()
+	delete(p.entries, image)
+	p.mu.Unlock()
+
+	return p.runtime.RemoveImage(image)
+}
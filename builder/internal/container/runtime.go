@@ -0,0 +1,84 @@
+package container
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// Runtime abstracts the container engine a worker builds ImageBuilder
+// containers on, so the builder package can run and observe a build without
+// caring whether the host has Podman or Docker installed.
+type Runtime interface {
+	RunContainer(opts ContainerRunOptions) (string, error)
+	PullImage(image string) error
+	PullImageWithProgress(image string, onProgress func(PullProgress)) error
+	ImageExists(image string) (bool, error)
+	RemoveImage(image string) error
+	Stop(containerID string, timeout time.Duration) error
+	RemoveContainer(containerID string) error
+	CopyFrom(containerID, srcPath, dstPath string) error
+	CopyTo(containerID, srcPath, dstPath string) error
+	Stats(containerID string) (ContainerStats, error)
+	Events(ctx context.Context) (<-chan Event, error)
+	// SupportsBindMounts reports whether RunContainer can bind-mount a host
+	// path directly (true for Docker and a local Podman socket). A remote
+	// Podman socket can't, so callers that want a mount's writes to persist
+	// on the host (e.g. buildcache.Manager's cache directories) must check
+	// this before relying on ModeBind.
+	SupportsBindMounts() bool
+}
+
+// PullProgress is a single progress update observed while pulling an
+// image, normalized from Docker's newline-delimited JSON pull stream (id,
+// status, progressDetail{current,total}). Runtimes that can't report
+// per-layer detail (e.g. Podman, see PodmanManager.PullImageWithProgress)
+// report coarse status updates instead, leaving Current/Total zero.
+type PullProgress struct {
+	ID      string
+	Status  string
+	Current int64
+	Total   int64
+}
+
+// ContainerStats is a point-in-time resource usage sample for a container.
+// BlockReadBytes/BlockWriteBytes and NetRxBytes/NetTxBytes are cumulative
+// counters since the container started, not deltas since the last sample.
+type ContainerStats struct {
+	CPUPercent      float64
+	MemUsageBytes   uint64
+	MemLimitBytes   uint64
+	BlockReadBytes  uint64
+	BlockWriteBytes uint64
+	NetRxBytes      uint64
+	NetTxBytes      uint64
+}
+
+// Event is a single container lifecycle event (e.g. "create", "start",
+// "die"), normalized across runtimes. Labels carries the container's labels
+// (asu.build.hash among them), letting EventStream pick out the events for
+// a single build's container out of the runtime's full event feed.
+type Event struct {
+	Type        string
+	Status      string
+	ContainerID string
+	Time        time.Time
+	Labels      map[string]string
+}
+
+// NewRuntime returns the Runtime implementation selected by runtime
+// ("podman" or "docker"). socketPath is only used by the Podman backend: a
+// bare path for a local socket, or a full connection URI (e.g.
+// tcp://host:2376) for a remote one, see PodmanManager. The Docker backend
+// picks up its connection from the standard DOCKER_HOST/TLS environment
+// variables.
+func NewRuntime(runtime, socketPath string) (Runtime, error) {
+	switch runtime {
+	case "podman":
+		return NewPodmanManager(socketPath)
+	case "docker":
+		return NewDockerManager()
+	default:
+		return nil, fmt.Errorf("unknown container runtime: %s", runtime)
+	}
+}
@@ -0,0 +1,122 @@
+package container
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// statsPollInterval is how often a StatsCollector samples a build
+// container's resource usage. Short enough to catch peak usage during the
+// image-build phase, long enough not to load the container runtime.
+const statsPollInterval = 2 * time.Second
+
+// ResourceProfile summarizes a container's resource usage over its
+// lifetime, reduced from the samples a StatsCollector gathered while it ran.
+// SampleCount is 0 if the container exited before a single poll landed
+// (e.g. a sub-statsPollInterval run), in which case the other fields carry
+// no information and shouldn't be recorded as a real data point.
+type ResourceProfile struct {
+	SampleCount     int
+	PeakCPUPercent  float64
+	AvgCPUPercent   float64
+	PeakMemBytes    uint64
+	AvgMemBytes     uint64
+	BlockReadBytes  uint64
+	BlockWriteBytes uint64
+	NetRxBytes      uint64
+	NetTxBytes      uint64
+}
+
+// StatsCollector polls a running container's resource usage at a fixed
+// interval and reduces the samples into a ResourceProfile. It polls
+// Runtime.Stats rather than subscribing to each engine's native stats
+// stream, since both DockerManager and PodmanManager already expose a
+// point-in-time Stats call and a short poll interval is accurate enough
+// for a build's aggregate figures.
+type StatsCollector struct {
+	mu sync.Mutex
+
+	samples int
+	sumCPU  float64
+	sumMem  uint64
+	peakCPU float64
+	peakMem uint64
+
+	lastBlockRead  uint64
+	lastBlockWrite uint64
+	lastNetRx      uint64
+	lastNetTx      uint64
+}
+
+// NewStatsCollector creates an empty StatsCollector.
+func NewStatsCollector() *StatsCollector {
+	return &StatsCollector{}
+}
+
+// Run polls runtime for containerID's resource usage every
+// statsPollInterval until ctx is cancelled. It's meant to run in its own
+// goroutine for the lifetime of the container, stopped once it exits.
+func (c *StatsCollector) Run(ctx context.Context, runtime Runtime, containerID string) {
+	ticker := time.NewTicker(statsPollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			stats, err := runtime.Stats(containerID)
+			if err != nil {
+				// The container may already have exited between ticks; that's
+				// the common case near the end of a build, not worth logging.
+				continue
+			}
+			c.add(stats)
+		}
+	}
+}
+
+func (c *StatsCollector) add(s ContainerStats) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.samples++
+	c.sumCPU += s.CPUPercent
+	c.sumMem += s.MemUsageBytes
+	if s.CPUPercent > c.peakCPU {
+		c.peakCPU = s.CPUPercent
+	}
+	if s.MemUsageBytes > c.peakMem {
+		c.peakMem = s.MemUsageBytes
+	}
+
+	// Block/net I/O are cumulative counters, so the latest sample already
+	// holds the running total.
+	c.lastBlockRead = s.BlockReadBytes
+	c.lastBlockWrite = s.BlockWriteBytes
+	c.lastNetRx = s.NetRxBytes
+	c.lastNetTx = s.NetTxBytes
+}
+
+// Profile reduces the samples collected so far into a ResourceProfile.
+// Safe to call once Run has returned, or while it's still running.
+func (c *StatsCollector) Profile() ResourceProfile {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	profile := ResourceProfile{
+		SampleCount:     c.samples,
+		PeakCPUPercent:  c.peakCPU,
+		PeakMemBytes:    c.peakMem,
+		BlockReadBytes:  c.lastBlockRead,
+		BlockWriteBytes: c.lastBlockWrite,
+		NetRxBytes:      c.lastNetRx,
+		NetTxBytes:      c.lastNetTx,
+	}
+	if c.samples > 0 {
+		profile.AvgCPUPercent = c.sumCPU / float64(c.samples)
+		profile.AvgMemBytes = c.sumMem / uint64(c.samples)
+	}
+	return profile
+}
@@ -0,0 +1,46 @@
+package db
+
+import (
+	"fmt"
+
+	"github.com/aparcar/asu/builder/internal/models"
+)
+
+// RecordContainerEvent persists a single container lifecycle event for
+// requestHash.
+func (db *DB) RecordContainerEvent(requestHash string, ev models.BuildContainerEvent) error {
+	_, err := db.Exec(`
+		INSERT INTO build_container_events (request_hash, timestamp, type, status)
+		VALUES (?, ?, ?, ?)
+	`, requestHash, ev.Timestamp, ev.Type, ev.Status)
+	if err != nil {
+		return fmt.Errorf("failed to record container event for %s: %w", requestHash, err)
+	}
+	return nil
+}
+
+// GetBuildContainerEvents returns the persisted container lifecycle events
+// for requestHash, oldest first.
+func (db *DB) GetBuildContainerEvents(requestHash string) ([]models.BuildContainerEvent, error) {
+	rows, err := db.Query(`
+		SELECT timestamp, type, status
+		FROM build_container_events
+		WHERE request_hash = ?
+		ORDER BY id ASC
+	`, requestHash)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query container events for %s: %w", requestHash, err)
+	}
+	defer rows.Close()
+
+	var evs []models.BuildContainerEvent
+	for rows.Next() {
+		var ev models.BuildContainerEvent
+		if err := rows.Scan(&ev.Timestamp, &ev.Type, &ev.Status); err != nil {
+			return nil, fmt.Errorf("failed to scan container event: %w", err)
+		}
+		evs = append(evs, ev)
+	}
+
+	return evs, rows.Err()
+}
@@ -2,12 +2,28 @@ package db
 
 import (
 	"database/sql"
+	"errors"
 	"fmt"
+	"math"
+	"strings"
 	"time"
 
 	"github.com/aparcar/asu/builder/internal/models"
 )
 
+// ErrJobNotFound is returned by CancelBuildJob when requestHash has no
+// build job.
+var ErrJobNotFound = errors.New("build job not found")
+
+// ErrJobAlreadyFinished is returned by CancelBuildJob when the job is
+// already completed, failed, or cancelled and so can no longer be
+// cancelled.
+var ErrJobAlreadyFinished = errors.New("build job already finished")
+
+// ErrJobAlreadyLeased is returned by StartBuildJob when requestHash is no
+// longer pending, i.e. another caller won the race to lease it first.
+var ErrJobAlreadyLeased = errors.New("build job is no longer pending")
+
 // CreateBuildJob inserts a new build job
 func (db *DB) CreateBuildJob(job *models.BuildJob) (int64, error) {
 	query := `
@@ -32,29 +48,15 @@ func (db *DB) CreateBuildJob(job *models.BuildJob) (int64, error) {
 func (db *DB) GetBuildJob(requestHash string) (*models.BuildJob, error) {
 	query := `
 		SELECT id, request_hash, status, started_at, finished_at,
-			   build_cmd, manifest, error_message, worker_id, queue_position
+			   build_cmd, manifest, error_message, worker_id, queue_position,
+			   lease_expires_at, attempt_count, next_attempt_at, last_error_class
 		FROM build_jobs
 		WHERE request_hash = ?
 		ORDER BY id DESC
 		LIMIT 1
 	`
 
-	var job models.BuildJob
-	var startedAt, finishedAt sql.NullTime
-
-	err := db.QueryRow(query, requestHash).Scan(
-		&job.ID,
-		&job.RequestHash,
-		&job.Status,
-		&startedAt,
-		&finishedAt,
-		&job.BuildCmd,
-		&job.Manifest,
-		&job.ErrorMessage,
-		&job.WorkerID,
-		&job.QueuePosition,
-	)
-
+	job, err := scanBuildJob(db.QueryRow(query, requestHash))
 	if err == sql.ErrNoRows {
 		return nil, nil
 	}
@@ -62,27 +64,22 @@ func (db *DB) GetBuildJob(requestHash string) (*models.BuildJob, error) {
 		return nil, fmt.Errorf("failed to query build job: %w", err)
 	}
 
-	if startedAt.Valid {
-		job.StartedAt = &startedAt.Time
-	}
-	if finishedAt.Valid {
-		job.FinishedAt = &finishedAt.Time
-	}
-
-	return &job, nil
+	return job, nil
 }
 
-// GetPendingJobs retrieves all pending build jobs
+// GetPendingJobs retrieves all pending build jobs that are currently eligible
+// to run (i.e. not held back by a retry backoff window, see FailBuildJob).
 func (db *DB) GetPendingJobs() ([]*models.BuildJob, error) {
 	query := `
 		SELECT id, request_hash, status, started_at, finished_at,
-			   build_cmd, manifest, error_message, worker_id, queue_position
+			   build_cmd, manifest, error_message, worker_id, queue_position,
+			   lease_expires_at, attempt_count, next_attempt_at, last_error_class
 		FROM build_jobs
-		WHERE status = ?
+		WHERE status = ? AND (next_attempt_at IS NULL OR next_attempt_at <= ?)
 		ORDER BY id ASC
 	`
 
-	rows, err := db.Query(query, models.JobStatusPending)
+	rows, err := db.Query(query, models.JobStatusPending, time.Now())
 	if err != nil {
 		return nil, fmt.Errorf("failed to query pending jobs: %w", err)
 	}
@@ -90,36 +87,65 @@ func (db *DB) GetPendingJobs() ([]*models.BuildJob, error) {
 
 	var jobs []*models.BuildJob
 	for rows.Next() {
-		var job models.BuildJob
-		var startedAt, finishedAt sql.NullTime
-
-		err := rows.Scan(
-			&job.ID,
-			&job.RequestHash,
-			&job.Status,
-			&startedAt,
-			&finishedAt,
-			&job.BuildCmd,
-			&job.Manifest,
-			&job.ErrorMessage,
-			&job.WorkerID,
-			&job.QueuePosition,
-		)
+		job, err := scanBuildJob(rows)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan job row: %w", err)
 		}
+		jobs = append(jobs, job)
+	}
 
-		if startedAt.Valid {
-			job.StartedAt = &startedAt.Time
-		}
-		if finishedAt.Valid {
-			job.FinishedAt = &finishedAt.Time
-		}
+	return jobs, rows.Err()
+}
 
-		jobs = append(jobs, &job)
+// rowScanner is satisfied by both *sql.Row and *sql.Rows.
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanBuildJob(row rowScanner) (*models.BuildJob, error) {
+	var job models.BuildJob
+	var startedAt, finishedAt, leaseExpiresAt, nextAttemptAt sql.NullTime
+	var buildCmd, manifest, errorMessage, workerID, lastErrorClass sql.NullString
+
+	err := row.Scan(
+		&job.ID,
+		&job.RequestHash,
+		&job.Status,
+		&startedAt,
+		&finishedAt,
+		&buildCmd,
+		&manifest,
+		&errorMessage,
+		&workerID,
+		&job.QueuePosition,
+		&leaseExpiresAt,
+		&job.AttemptCount,
+		&nextAttemptAt,
+		&lastErrorClass,
+	)
+	if err != nil {
+		return nil, err
 	}
 
-	return jobs, rows.Err()
+	if startedAt.Valid {
+		job.StartedAt = &startedAt.Time
+	}
+	if finishedAt.Valid {
+		job.FinishedAt = &finishedAt.Time
+	}
+	if leaseExpiresAt.Valid {
+		job.LeaseExpiresAt = &leaseExpiresAt.Time
+	}
+	if nextAttemptAt.Valid {
+		job.NextAttemptAt = &nextAttemptAt.Time
+	}
+	job.BuildCmd = buildCmd.String
+	job.Manifest = manifest.String
+	job.ErrorMessage = errorMessage.String
+	job.WorkerID = workerID.String
+	job.LastErrorClass = lastErrorClass.String
+
+	return &job, nil
 }
 
 // UpdateJobStatus updates the status of a build job
@@ -129,40 +155,348 @@ func (db *DB) UpdateJobStatus(requestHash string, status models.JobStatus) error
 	return err
 }
 
-// StartBuildJob marks a job as started
-func (db *DB) StartBuildJob(requestHash, workerID string) error {
+// StartBuildJob marks a job as started, assigning it to workerID and
+// granting a lease valid for leaseDuration. The worker must call RenewLease
+// periodically to keep the job from being reclaimed by the sweeper. It only
+// acquires the lease if the job is still pending, so two callers racing to
+// lease the same job (e.g. two runner processes polling
+// POST /api/v1/internal/lease concurrently) cannot both win: the loser gets
+// ErrJobAlreadyLeased and should try the next pending job instead.
+func (db *DB) StartBuildJob(requestHash, workerID string, leaseDuration time.Duration) error {
 	query := `
 		UPDATE build_jobs
-		SET status = ?, started_at = ?, worker_id = ?
-		WHERE request_hash = ?
+		SET status = ?, started_at = ?, worker_id = ?, lease_expires_at = ?
+		WHERE request_hash = ? AND status = ?
 	`
 
-	_, err := db.Exec(query, models.JobStatusBuilding, time.Now(), workerID, requestHash)
-	return err
+	now := time.Now()
+	result, err := db.Exec(query, models.JobStatusBuilding, now, workerID, now.Add(leaseDuration), requestHash, models.JobStatusPending)
+	if err != nil {
+		return fmt.Errorf("failed to start build job %s: %w", requestHash, err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to check started build job %s: %w", requestHash, err)
+	}
+	if rows == 0 {
+		return ErrJobAlreadyLeased
+	}
+
+	return nil
 }
 
-// CompleteBuildJob marks a job as completed
-func (db *DB) CompleteBuildJob(requestHash, buildCmd, manifest string) error {
+// RenewLease extends the lease on a job, but only if workerID still owns it.
+// It returns sql.ErrNoRows if the lease was not (or no longer) held by workerID.
+func (db *DB) RenewLease(requestHash, workerID string, duration time.Duration) error {
+	query := `
+		UPDATE build_jobs
+		SET lease_expires_at = ?
+		WHERE request_hash = ? AND worker_id = ? AND status = ?
+	`
+
+	result, err := db.Exec(query, time.Now().Add(duration), requestHash, workerID, models.JobStatusBuilding)
+	if err != nil {
+		return fmt.Errorf("failed to renew lease for %s: %w", requestHash, err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to check renewed lease for %s: %w", requestHash, err)
+	}
+	if rows == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+// RenewLeases batch-renews leases for multiple request hashes owned by
+// workerID, to cut down on round trips when a worker holds many jobs.
+func (db *DB) RenewLeases(requestHashes []string, workerID string, duration time.Duration) error {
+	if len(requestHashes) == 0 {
+		return nil
+	}
+
+	placeholders := make([]string, len(requestHashes))
+	args := make([]interface{}, 0, len(requestHashes)+2)
+	args = append(args, time.Now().Add(duration))
+	for i, hash := range requestHashes {
+		placeholders[i] = "?"
+		args = append(args, hash)
+	}
+	args = append(args, workerID)
+
+	query := fmt.Sprintf(`
+		UPDATE build_jobs
+		SET lease_expires_at = ?
+		WHERE request_hash IN (%s) AND worker_id = ? AND status = ?
+	`, strings.Join(placeholders, ","))
+	args = append(args, models.JobStatusBuilding)
+
+	_, err := db.Exec(query, args...)
+	if err != nil {
+		return fmt.Errorf("failed to batch-renew leases: %w", err)
+	}
+	return nil
+}
+
+// CompleteBuildJob marks a job as completed, but only if workerID still
+// holds an unexpired lease on it.
+func (db *DB) CompleteBuildJob(requestHash, workerID, buildCmd, manifest string) error {
 	query := `
 		UPDATE build_jobs
 		SET status = ?, finished_at = ?, build_cmd = ?, manifest = ?
-		WHERE request_hash = ?
+		WHERE request_hash = ? AND worker_id = ? AND lease_expires_at > ?
 	`
 
-	_, err := db.Exec(query, models.JobStatusCompleted, time.Now(), buildCmd, manifest, requestHash)
-	return err
+	result, err := db.Exec(query, models.JobStatusCompleted, time.Now(), buildCmd, manifest, requestHash, workerID, time.Now())
+	if err != nil {
+		return fmt.Errorf("failed to complete build job: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to check completed build job: %w", err)
+	}
+	if rows == 0 {
+		return fmt.Errorf("lease for %s is no longer held by worker %s", requestHash, workerID)
+	}
+
+	return nil
+}
+
+// RetryPolicy bounds how many times a transiently failed job is retried and
+// how long it backs off between attempts.
+type RetryPolicy struct {
+	MaxAttempts    int
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+	Multiplier     float64
+}
+
+// backoffFor returns the delay before the next attempt after attemptCount
+// prior attempts, growing exponentially up to MaxBackoff.
+func (p RetryPolicy) backoffFor(attemptCount int) time.Duration {
+	backoff := float64(p.InitialBackoff) * math.Pow(p.Multiplier, float64(attemptCount))
+	if d := time.Duration(backoff); d < p.MaxBackoff {
+		return d
+	}
+	return p.MaxBackoff
+}
+
+// FailBuildJob marks a job as failed, but only if workerID still holds an
+// unexpired lease on it. A class of FailureTransient requeues the job for
+// another attempt (delayed by policy's exponential backoff) as long as it
+// hasn't used up its retry budget; anything else, or a transient failure
+// past MaxAttempts, is left failed for good.
+func (db *DB) FailBuildJob(requestHash, workerID, errorMessage string, class models.FailureClass, policy RetryPolicy) error {
+	var attemptCount int
+	err := db.QueryRow(`
+		SELECT attempt_count FROM build_jobs
+		WHERE request_hash = ? AND worker_id = ? AND lease_expires_at > ?
+	`, requestHash, workerID, time.Now()).Scan(&attemptCount)
+	if err == sql.ErrNoRows {
+		return fmt.Errorf("lease for %s is no longer held by worker %s", requestHash, workerID)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to look up attempt count for %s: %w", requestHash, err)
+	}
+
+	if class == models.FailureTransient && attemptCount < policy.MaxAttempts {
+		nextAttempt := time.Now().Add(policy.backoffFor(attemptCount))
+
+		result, err := db.Exec(`
+			UPDATE build_jobs
+			SET status = ?, started_at = NULL, worker_id = '', last_error_class = ?,
+				attempt_count = attempt_count + 1, next_attempt_at = ?
+			WHERE request_hash = ? AND worker_id = ? AND lease_expires_at > ?
+		`, models.JobStatusPending, class, nextAttempt, requestHash, workerID, time.Now())
+		if err != nil {
+			return fmt.Errorf("failed to requeue build job %s: %w", requestHash, err)
+		}
+		rows, err := result.RowsAffected()
+		if err != nil {
+			return fmt.Errorf("failed to check requeued build job %s: %w", requestHash, err)
+		}
+		if rows == 0 {
+			return fmt.Errorf("lease for %s is no longer held by worker %s", requestHash, workerID)
+		}
+		return nil
+	}
+
+	result, err := db.Exec(`
+		UPDATE build_jobs
+		SET status = ?, finished_at = ?, error_message = ?, last_error_class = ?, attempt_count = attempt_count + 1
+		WHERE request_hash = ? AND worker_id = ? AND lease_expires_at > ?
+	`, models.JobStatusFailed, time.Now(), errorMessage, class, requestHash, workerID, time.Now())
+	if err != nil {
+		return fmt.Errorf("failed to fail build job: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to check failed build job: %w", err)
+	}
+	if rows == 0 {
+		return fmt.Errorf("lease for %s is no longer held by worker %s", requestHash, workerID)
+	}
+
+	return nil
 }
 
-// FailBuildJob marks a job as failed
-func (db *DB) FailBuildJob(requestHash, errorMessage string) error {
+// CancelBuildJob cancels a build job, returning its resulting status. A
+// pending job is cancelled immediately, freeing its queue slot. A building
+// job is marked cancelling instead: the owning worker observes this on its
+// next cancellation poll, kills the build, and transitions it to cancelled
+// itself via CancelRunningJob. Cancelling an already-cancelling job is a
+// no-op that just reports the current status. It returns ErrJobNotFound or
+// ErrJobAlreadyFinished for jobs that cannot be cancelled.
+func (db *DB) CancelBuildJob(requestHash string) (models.JobStatus, error) {
+	job, err := db.GetBuildJob(requestHash)
+	if err != nil {
+		return "", fmt.Errorf("failed to look up build job: %w", err)
+	}
+	if job == nil {
+		return "", ErrJobNotFound
+	}
+
+	switch job.Status {
+	case models.JobStatusCancelling:
+		return models.JobStatusCancelling, nil
+	case models.JobStatusPending:
+		result, err := db.Exec(`
+			UPDATE build_jobs
+			SET status = ?, finished_at = ?, error_message = ?
+			WHERE request_hash = ? AND status = ?
+		`, models.JobStatusCancelled, time.Now(), "cancelled by user", requestHash, models.JobStatusPending)
+		if err != nil {
+			return "", fmt.Errorf("failed to cancel pending build job: %w", err)
+		}
+		if rows, err := result.RowsAffected(); err != nil || rows == 0 {
+			return "", ErrJobAlreadyFinished
+		}
+		return models.JobStatusCancelled, nil
+	case models.JobStatusBuilding:
+		result, err := db.Exec(`
+			UPDATE build_jobs
+			SET status = ?
+			WHERE request_hash = ? AND status = ?
+		`, models.JobStatusCancelling, requestHash, models.JobStatusBuilding)
+		if err != nil {
+			return "", fmt.Errorf("failed to request cancellation of build job: %w", err)
+		}
+		if rows, err := result.RowsAffected(); err != nil || rows == 0 {
+			return "", ErrJobAlreadyFinished
+		}
+		return models.JobStatusCancelling, nil
+	default:
+		return "", ErrJobAlreadyFinished
+	}
+}
+
+// IsCancelRequested reports whether requestHash's job is in the cancelling
+// state, i.e. a user requested cancellation while it was building. Workers
+// poll this to know when to kill the underlying build.
+func (db *DB) IsCancelRequested(requestHash string) (bool, error) {
+	job, err := db.GetBuildJob(requestHash)
+	if err != nil {
+		return false, fmt.Errorf("failed to look up build job: %w", err)
+	}
+	if job == nil {
+		return false, nil
+	}
+	return job.Status == models.JobStatusCancelling, nil
+}
+
+// CancelRunningJob transitions a cancelling job to cancelled once its
+// worker has killed the underlying build, but only if workerID still holds
+// an unexpired lease on it (mirroring FailBuildJob/CompleteBuildJob).
+func (db *DB) CancelRunningJob(requestHash, workerID string) error {
 	query := `
 		UPDATE build_jobs
 		SET status = ?, finished_at = ?, error_message = ?
-		WHERE request_hash = ?
+		WHERE request_hash = ? AND worker_id = ? AND lease_expires_at > ?
 	`
 
-	_, err := db.Exec(query, models.JobStatusFailed, time.Now(), errorMessage, requestHash)
-	return err
+	result, err := db.Exec(query, models.JobStatusCancelled, time.Now(), "cancelled by user", requestHash, workerID, time.Now())
+	if err != nil {
+		return fmt.Errorf("failed to cancel build job: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to check cancelled build job: %w", err)
+	}
+	if rows == 0 {
+		return fmt.Errorf("lease for %s is no longer held by worker %s", requestHash, workerID)
+	}
+
+	return nil
+}
+
+// ReclaimStuckJobs resets jobs whose lease has expired back to pending so
+// another worker can pick them up, incrementing their attempt_count. Jobs
+// that have already exhausted maxAttempts are transitioned to failed with a
+// synthetic error instead of being requeued, so a crash-looping job cannot
+// be reclaimed forever. It returns the number of jobs reclaimed (requeued
+// or permanently failed).
+func (db *DB) ReclaimStuckJobs(maxAttempts int) (int, error) {
+	now := time.Now()
+
+	rows, err := db.Query(`
+		SELECT request_hash, attempt_count
+		FROM build_jobs
+		WHERE status = ? AND lease_expires_at < ?
+	`, models.JobStatusBuilding, now)
+	if err != nil {
+		return 0, fmt.Errorf("failed to query stuck jobs: %w", err)
+	}
+
+	type stuckJob struct {
+		requestHash  string
+		attemptCount int
+	}
+	var stuck []stuckJob
+	for rows.Next() {
+		var j stuckJob
+		if err := rows.Scan(&j.requestHash, &j.attemptCount); err != nil {
+			rows.Close()
+			return 0, fmt.Errorf("failed to scan stuck job: %w", err)
+		}
+		stuck = append(stuck, j)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	reclaimed := 0
+	for _, j := range stuck {
+		if j.attemptCount+1 >= maxAttempts {
+			_, err := db.Exec(`
+				UPDATE build_jobs
+				SET status = ?, finished_at = ?, error_message = ?, attempt_count = attempt_count + 1
+				WHERE request_hash = ? AND status = ? AND lease_expires_at < ?
+			`, models.JobStatusFailed, now, "exceeded retry budget: lease repeatedly expired without completion",
+				j.requestHash, models.JobStatusBuilding, now)
+			if err != nil {
+				return reclaimed, fmt.Errorf("failed to fail stuck job %s: %w", j.requestHash, err)
+			}
+		} else {
+			_, err := db.Exec(`
+				UPDATE build_jobs
+				SET status = ?, started_at = NULL, worker_id = '', lease_expires_at = NULL, attempt_count = attempt_count + 1
+				WHERE request_hash = ? AND status = ? AND lease_expires_at < ?
+			`, models.JobStatusPending, j.requestHash, models.JobStatusBuilding, now)
+			if err != nil {
+				return reclaimed, fmt.Errorf("failed to requeue stuck job %s: %w", j.requestHash, err)
+			}
+		}
+		reclaimed++
+	}
+
+	return reclaimed, nil
 }
 
 // GetQueueLength returns the number of pending jobs
@@ -172,7 +506,8 @@ func (db *DB) GetQueueLength() (int, error) {
 	return count, err
 }
 
-// GetQueuePosition returns the position of a job in the queue
+// GetQueuePosition returns the position of a job among currently eligible
+// pending jobs (i.e. not held back by a retry backoff window).
 func (db *DB) GetQueuePosition(requestHash string) (int, error) {
 	job, err := db.GetBuildJob(requestHash)
 	if err != nil {
@@ -186,8 +521,8 @@ func (db *DB) GetQueuePosition(requestHash string) (int, error) {
 	query := `
 		SELECT COUNT(*) + 1
 		FROM build_jobs
-		WHERE status = ? AND id < ?
+		WHERE status = ? AND id < ? AND (next_attempt_at IS NULL OR next_attempt_at <= ?)
 	`
-	err = db.QueryRow(query, models.JobStatusPending, job.ID).Scan(&position)
+	err = db.QueryRow(query, models.JobStatusPending, job.ID, time.Now()).Scan(&position)
 	return position, err
 }
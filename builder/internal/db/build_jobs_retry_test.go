@@ -0,0 +1,31 @@
+package db
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRetryPolicyBackoffFor(t *testing.T) {
+	policy := RetryPolicy{
+		MaxAttempts:    3,
+		InitialBackoff: 5 * time.Second,
+		MaxBackoff:     1 * time.Minute,
+		Multiplier:     3.0,
+	}
+
+	cases := []struct {
+		attemptCount int
+		want         time.Duration
+	}{
+		{0, 5 * time.Second},
+		{1, 15 * time.Second},
+		{2, 45 * time.Second},
+		{3, 1 * time.Minute}, // clamped to MaxBackoff
+	}
+
+	for _, c := range cases {
+		if got := policy.backoffFor(c.attemptCount); got != c.want {
+			t.Errorf("backoffFor(%d) = %v, want %v", c.attemptCount, got, c.want)
+		}
+	}
+}
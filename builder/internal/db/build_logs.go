@@ -0,0 +1,108 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/aparcar/asu/builder/internal/models"
+)
+
+// AppendBuildLog persists a log line for requestHash and trims the oldest
+// lines once the persisted tail exceeds maxBytes, so a long-running build
+// can't grow build_logs without bound. It returns the line's monotonic
+// sequence number (its row id), which a client can later pass as ?since= to
+// resume a polling or SSE subscription without re-reading lines it already
+// has.
+func (db *DB) AppendBuildLog(requestHash string, line models.BuildLogLine, maxBytes int) (int64, error) {
+	res, err := db.Exec(`
+		INSERT INTO build_logs (request_hash, timestamp, stream, text, step, byte_size)
+		VALUES (?, ?, ?, ?, ?, ?)
+	`, requestHash, line.Timestamp, line.Stream, line.Text, line.Step, len(line.Text))
+	if err != nil {
+		return 0, fmt.Errorf("failed to append build log for %s: %w", requestHash, err)
+	}
+
+	seq, err := res.LastInsertId()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get sequence number for build log line: %w", err)
+	}
+
+	if _, err := db.Exec(`
+		DELETE FROM build_logs
+		WHERE request_hash = ? AND id NOT IN (
+			SELECT id FROM (
+				SELECT id, SUM(byte_size) OVER (ORDER BY id DESC) AS running_bytes
+				FROM build_logs
+				WHERE request_hash = ?
+			) WHERE running_bytes <= ?
+		)
+	`, requestHash, requestHash, maxBytes); err != nil {
+		return seq, fmt.Errorf("failed to trim build log tail for %s: %w", requestHash, err)
+	}
+
+	return seq, nil
+}
+
+// GetBuildLogTail returns the persisted tail of log lines for requestHash,
+// oldest first. If step is non-empty, only lines from that build phase are
+// returned, so a client can isolate a single step's output (e.g. ?step=depsolve
+// on the SSE endpoint).
+func (db *DB) GetBuildLogTail(requestHash, step string) ([]models.BuildLogLine, error) {
+	var rows *sql.Rows
+	var err error
+
+	if step == "" {
+		rows, err = db.Query(`
+			SELECT id, timestamp, stream, text, step
+			FROM build_logs
+			WHERE request_hash = ?
+			ORDER BY id ASC
+		`, requestHash)
+	} else {
+		rows, err = db.Query(`
+			SELECT id, timestamp, stream, text, step
+			FROM build_logs
+			WHERE request_hash = ? AND step = ?
+			ORDER BY id ASC
+		`, requestHash, step)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to query build log tail for %s: %w", requestHash, err)
+	}
+	defer rows.Close()
+
+	return scanBuildLogLines(rows)
+}
+
+// GetBuildLogsSince returns the persisted log lines for requestHash with a
+// sequence number greater than since, oldest first, for clients polling
+// GET .../logs?since=<seq> instead of holding open an SSE connection.
+func (db *DB) GetBuildLogsSince(requestHash string, since int64) ([]models.BuildLogLine, error) {
+	rows, err := db.Query(`
+		SELECT id, timestamp, stream, text, step
+		FROM build_logs
+		WHERE request_hash = ? AND id > ?
+		ORDER BY id ASC
+	`, requestHash, since)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query build logs since %d for %s: %w", since, requestHash, err)
+	}
+	defer rows.Close()
+
+	return scanBuildLogLines(rows)
+}
+
+func scanBuildLogLines(rows *sql.Rows) ([]models.BuildLogLine, error) {
+	var lines []models.BuildLogLine
+	for rows.Next() {
+		var line models.BuildLogLine
+		var lineStep sql.NullString
+		if err := rows.Scan(&line.Seq, &line.Timestamp, &line.Stream, &line.Text, &lineStep); err != nil {
+			return nil, fmt.Errorf("failed to scan build log line: %w", err)
+		}
+		line.Step = lineStep.String
+		lines = append(lines, line)
+	}
+
+	return lines, rows.Err()
+}
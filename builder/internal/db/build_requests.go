@@ -1,16 +1,23 @@
 package db
 
 import (
+	"context"
 	"database/sql"
 	"encoding/json"
 	"fmt"
-	"time"
 
 	"github.com/aparcar/asu/builder/internal/models"
+	"go.opentelemetry.io/otel"
 )
 
-// CreateBuildRequest inserts a new build request into the database
-func (db *DB) CreateBuildRequest(req *models.BuildRequest) error {
+// CreateBuildRequest inserts a new build request into the database. ctx is
+// used only to attach this call's OpenTelemetry span to its caller's trace
+// (see the telemetry package) — the underlying driver call isn't
+// context-aware.
+func (db *DB) CreateBuildRequest(ctx context.Context, req *models.BuildRequest) error {
+	_, span := otel.Tracer("internal/db").Start(ctx, "db.create_build_request")
+	defer span.End()
+
 	packagesJSON, err := req.PackagesJSON()
 	if err != nil {
 		return fmt.Errorf("failed to marshal packages: %w", err)
@@ -117,6 +124,132 @@ func (db *DB) GetBuildRequest(requestHash string) (*models.BuildRequest, error)
 	return &req, nil
 }
 
+// ListBuildRequestsByClient returns the most recent build requests submitted
+// by client (see BuildRequest.Client, populated from the authenticated
+// username by handleBuildRequest), most recent first.
+func (db *DB) ListBuildRequestsByClient(client string, limit int) ([]*models.BuildRequest, error) {
+	rows, err := db.Query(`
+		SELECT request_hash, distro, version, target, profile,
+			   packages, packages_versions, defaults, rootfs_size_mb,
+			   repositories, repository_keys, diff_packages, client, created_at
+		FROM build_requests
+		WHERE client = ?
+		ORDER BY created_at DESC
+		LIMIT ?
+	`, client, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query build requests for client: %w", err)
+	}
+	defer rows.Close()
+
+	var requests []*models.BuildRequest
+	for rows.Next() {
+		var req models.BuildRequest
+		var packagesJSON, packagesVersionsJSON, repositoriesJSON, repositoryKeysJSON string
+
+		if err := rows.Scan(
+			&req.RequestHash,
+			&req.Distro,
+			&req.Version,
+			&req.Target,
+			&req.Profile,
+			&packagesJSON,
+			&packagesVersionsJSON,
+			&req.Defaults,
+			&req.RootfsSizeMB,
+			&repositoriesJSON,
+			&repositoryKeysJSON,
+			&req.DiffPackages,
+			&req.Client,
+			&req.CreatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan build request: %w", err)
+		}
+
+		json.Unmarshal([]byte(packagesJSON), &req.Packages)
+		json.Unmarshal([]byte(packagesVersionsJSON), &req.PackagesVersions)
+		json.Unmarshal([]byte(repositoriesJSON), &req.Repositories)
+		json.Unmarshal([]byte(repositoryKeysJSON), &req.RepositoryKeys)
+
+		requests = append(requests, &req)
+	}
+
+	return requests, rows.Err()
+}
+
+// ListDistinctBuildTargets returns every distinct (distro, version, target)
+// combination seen in build_requests, for the pkgupdates package to fetch
+// and cache upstream package index metadata against.
+func (db *DB) ListDistinctBuildTargets() ([]models.BuildTarget, error) {
+	rows, err := db.Query(`SELECT DISTINCT distro, version, target FROM build_requests`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query distinct build targets: %w", err)
+	}
+	defer rows.Close()
+
+	var targets []models.BuildTarget
+	for rows.Next() {
+		var t models.BuildTarget
+		if err := rows.Scan(&t.Distro, &t.Version, &t.Target); err != nil {
+			return nil, fmt.Errorf("failed to scan build target: %w", err)
+		}
+		targets = append(targets, t)
+	}
+
+	return targets, rows.Err()
+}
+
+// ListBuildRequestsForTarget returns every build request recorded for the
+// given (distro, version, target), for the pkgupdates package to check for
+// outdated packages and, if config.AutoRebuildOnUpdate is set, re-enqueue.
+func (db *DB) ListBuildRequestsForTarget(distro, version, target string) ([]*models.BuildRequest, error) {
+	rows, err := db.Query(`
+		SELECT request_hash, distro, version, target, profile,
+			   packages, packages_versions, defaults, rootfs_size_mb,
+			   repositories, repository_keys, diff_packages, client, created_at
+		FROM build_requests
+		WHERE distro = ? AND version = ? AND target = ?
+	`, distro, version, target)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query build requests for target: %w", err)
+	}
+	defer rows.Close()
+
+	var requests []*models.BuildRequest
+	for rows.Next() {
+		var req models.BuildRequest
+		var packagesJSON, packagesVersionsJSON, repositoriesJSON, repositoryKeysJSON string
+
+		if err := rows.Scan(
+			&req.RequestHash,
+			&req.Distro,
+			&req.Version,
+			&req.Target,
+			&req.Profile,
+			&packagesJSON,
+			&packagesVersionsJSON,
+			&req.Defaults,
+			&req.RootfsSizeMB,
+			&repositoriesJSON,
+			&repositoryKeysJSON,
+			&req.DiffPackages,
+			&req.Client,
+			&req.CreatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan build request: %w", err)
+		}
+
+		json.Unmarshal([]byte(packagesJSON), &req.Packages)
+		json.Unmarshal([]byte(packagesVersionsJSON), &req.PackagesVersions)
+		json.Unmarshal([]byte(repositoriesJSON), &req.Repositories)
+		json.Unmarshal([]byte(repositoryKeysJSON), &req.RepositoryKeys)
+
+		requests = append(requests, &req)
+	}
+
+	return requests, rows.Err()
+}
+
 // BuildRequestExists checks if a build request exists
 func (db *DB) BuildRequestExists(requestHash string) (bool, error) {
 	var count int
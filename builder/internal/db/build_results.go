@@ -13,10 +13,15 @@ import (
 func (db *DB) CreateBuildResult(result *models.BuildResult) error {
 	query := `
 		INSERT INTO build_results (
-			request_hash, images, manifest, build_at, cache_hit, build_duration_seconds
-		) VALUES (?, ?, ?, ?, ?, ?)
+			request_hash, images, manifest, build_at, cache_hit, build_duration_seconds,
+			signatures, signing_key_fingerprint, artifact_urls
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
 	`
 
+	if result.ArtifactURLs == "" {
+		result.ArtifactURLs = "{}"
+	}
+
 	_, err := db.Exec(query,
 		result.RequestHash,
 		result.Images,
@@ -24,6 +29,9 @@ func (db *DB) CreateBuildResult(result *models.BuildResult) error {
 		result.BuildAt,
 		result.CacheHit,
 		result.BuildDurationSecs,
+		result.Signatures,
+		result.SigningKeyFingerprint,
+		result.ArtifactURLs,
 	)
 
 	if err != nil {
@@ -36,7 +44,8 @@ func (db *DB) CreateBuildResult(result *models.BuildResult) error {
 // GetBuildResult retrieves a build result by request hash
 func (db *DB) GetBuildResult(requestHash string) (*models.BuildResult, error) {
 	query := `
-		SELECT request_hash, images, manifest, build_at, cache_hit, build_duration_seconds
+		SELECT request_hash, images, manifest, build_at, cache_hit, build_duration_seconds,
+			signatures, signing_key_fingerprint, artifact_urls
 		FROM build_results
 		WHERE request_hash = ?
 	`
@@ -50,6 +59,9 @@ func (db *DB) GetBuildResult(requestHash string) (*models.BuildResult, error) {
 		&result.BuildAt,
 		&result.CacheHit,
 		&result.BuildDurationSecs,
+		&result.Signatures,
+		&result.SigningKeyFingerprint,
+		&result.ArtifactURLs,
 	)
 
 	if err == sql.ErrNoRows {
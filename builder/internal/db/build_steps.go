@@ -0,0 +1,138 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/aparcar/asu/builder/internal/models"
+)
+
+// StartStep records that requestHash's current job has begun phase name,
+// owned by workerID. Restarting an already-started step (e.g. after a
+// reclaimed lease retries the build) resets it rather than erroring.
+func (db *DB) StartStep(requestHash string, name models.StepName, workerID string) error {
+	job, err := db.GetBuildJob(requestHash)
+	if err != nil {
+		return fmt.Errorf("failed to look up build job: %w", err)
+	}
+	if job == nil {
+		return fmt.Errorf("build job not found for %s", requestHash)
+	}
+
+	_, err = db.Exec(`
+		INSERT INTO build_steps (job_id, name, status, started_at)
+		VALUES (?, ?, ?, ?)
+		ON CONFLICT(job_id, name) DO UPDATE SET
+			status = excluded.status,
+			started_at = excluded.started_at,
+			finished_at = NULL,
+			error_message = NULL,
+			exit_code = NULL
+	`, job.ID, name, models.StepStatusRunning, time.Now())
+	if err != nil {
+		return fmt.Errorf("failed to start step %s for %s (worker %s): %w", name, requestHash, workerID, err)
+	}
+
+	return nil
+}
+
+// CompleteStep marks phase name of requestHash's current job as completed.
+func (db *DB) CompleteStep(requestHash string, name models.StepName) error {
+	job, err := db.GetBuildJob(requestHash)
+	if err != nil {
+		return fmt.Errorf("failed to look up build job: %w", err)
+	}
+	if job == nil {
+		return fmt.Errorf("build job not found for %s", requestHash)
+	}
+
+	_, err = db.Exec(`
+		UPDATE build_steps
+		SET status = ?, finished_at = ?
+		WHERE job_id = ? AND name = ?
+	`, models.StepStatusCompleted, time.Now(), job.ID, name)
+	if err != nil {
+		return fmt.Errorf("failed to complete step %s for %s: %w", name, requestHash, err)
+	}
+
+	return nil
+}
+
+// FailStep marks phase name of requestHash's current job as failed with
+// errMsg. exitCode is the underlying process's exit code, or -1 if the
+// runtime doesn't expose one for this failure.
+func (db *DB) FailStep(requestHash string, name models.StepName, errMsg string, exitCode int) error {
+	job, err := db.GetBuildJob(requestHash)
+	if err != nil {
+		return fmt.Errorf("failed to look up build job: %w", err)
+	}
+	if job == nil {
+		return fmt.Errorf("build job not found for %s", requestHash)
+	}
+
+	_, err = db.Exec(`
+		UPDATE build_steps
+		SET status = ?, finished_at = ?, error_message = ?, exit_code = ?
+		WHERE job_id = ? AND name = ?
+	`, models.StepStatusFailed, time.Now(), errMsg, exitCode, job.ID, name)
+	if err != nil {
+		return fmt.Errorf("failed to fail step %s for %s: %w", name, requestHash, err)
+	}
+
+	return nil
+}
+
+// GetSteps returns the build steps recorded so far for requestHash's
+// current job, oldest first.
+func (db *DB) GetSteps(requestHash string) ([]models.BuildStep, error) {
+	job, err := db.GetBuildJob(requestHash)
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up build job: %w", err)
+	}
+	if job == nil {
+		return nil, nil
+	}
+
+	rows, err := db.Query(`
+		SELECT id, job_id, name, status, started_at, finished_at, error_message, exit_code
+		FROM build_steps
+		WHERE job_id = ?
+		ORDER BY id ASC
+	`, job.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query build steps for %s: %w", requestHash, err)
+	}
+	defer rows.Close()
+
+	var steps []models.BuildStep
+	for rows.Next() {
+		var step models.BuildStep
+		var startedAt, finishedAt sql.NullTime
+		var errorMessage sql.NullString
+		var exitCode sql.NullInt64
+
+		if err := rows.Scan(
+			&step.ID, &step.JobID, &step.Name, &step.Status,
+			&startedAt, &finishedAt, &errorMessage, &exitCode,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan build step: %w", err)
+		}
+
+		if startedAt.Valid {
+			step.StartedAt = &startedAt.Time
+		}
+		if finishedAt.Valid {
+			step.FinishedAt = &finishedAt.Time
+		}
+		step.ErrorMessage = errorMessage.String
+		if exitCode.Valid {
+			code := int(exitCode.Int64)
+			step.ExitCode = &code
+		}
+
+		steps = append(steps, step)
+	}
+
+	return steps, rows.Err()
+}
@@ -0,0 +1,116 @@
+package db
+
+import (
+	"fmt"
+	"time"
+)
+
+// DownloadCacheEntry is one file tracked in the shared download cache (see
+// the buildcache package), used by its LRU sweeper to decide what to evict.
+type DownloadCacheEntry struct {
+	CacheKey   string    `json:"cache_key"`
+	SizeBytes  int64     `json:"size_bytes"`
+	LastUsedAt time.Time `json:"last_used_at"`
+}
+
+// UpsertDownloadCacheEntry records key's current size and marks it used at
+// lastUsed, for EvictDownloadCache to judge eviction order by.
+func (db *DB) UpsertDownloadCacheEntry(key string, size int64, lastUsed time.Time) error {
+	_, err := db.Exec(`
+		INSERT INTO download_cache_entries (cache_key, size_bytes, last_used_at)
+		VALUES (?, ?, ?)
+		ON CONFLICT (cache_key) DO UPDATE SET size_bytes = excluded.size_bytes, last_used_at = excluded.last_used_at
+	`, key, size, lastUsed)
+	if err != nil {
+		return fmt.Errorf("failed to upsert download cache entry %s: %w", key, err)
+	}
+	return nil
+}
+
+// ListDownloadCacheEntries returns every tracked download cache entry,
+// least-recently-used first, for EvictDownloadCache to evict from.
+func (db *DB) ListDownloadCacheEntries() ([]DownloadCacheEntry, error) {
+	rows, err := db.Query(`SELECT cache_key, size_bytes, last_used_at FROM download_cache_entries ORDER BY last_used_at ASC`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query download cache entries: %w", err)
+	}
+	defer rows.Close()
+
+	var entries []DownloadCacheEntry
+	for rows.Next() {
+		var e DownloadCacheEntry
+		if err := rows.Scan(&e.CacheKey, &e.SizeBytes, &e.LastUsedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan download cache entry: %w", err)
+		}
+		entries = append(entries, e)
+	}
+	return entries, rows.Err()
+}
+
+// DeleteDownloadCacheEntry forgets key once EvictDownloadCache has removed
+// its file from disk.
+func (db *DB) DeleteDownloadCacheEntry(key string) error {
+	_, err := db.Exec(`DELETE FROM download_cache_entries WHERE cache_key = ?`, key)
+	if err != nil {
+		return fmt.Errorf("failed to delete download cache entry %s: %w", key, err)
+	}
+	return nil
+}
+
+// BuildCacheStat is a (version,target) pair's download cache hit rate,
+// returned by GetCacheStats for GET /api/v1/cache/stats.
+type BuildCacheStat struct {
+	Version string  `json:"version"`
+	Target  string  `json:"target"`
+	Hits    int     `json:"hits"`
+	Misses  int     `json:"misses"`
+	HitRate float64 `json:"hit_rate"`
+}
+
+// RecordCacheHit records that version/target's build found everything it
+// needed already in the download cache.
+func (db *DB) RecordCacheHit(version, target string) error {
+	return db.bumpCacheStat(version, target, "hits")
+}
+
+// RecordCacheMiss records that version/target's build had to download at
+// least one file the cache didn't already have.
+func (db *DB) RecordCacheMiss(version, target string) error {
+	return db.bumpCacheStat(version, target, "misses")
+}
+
+func (db *DB) bumpCacheStat(version, target, column string) error {
+	query := fmt.Sprintf(`
+		INSERT INTO build_cache_stats (version, target, %s)
+		VALUES (?, ?, 1)
+		ON CONFLICT (version, target) DO UPDATE SET %s = %s + 1
+	`, column, column, column)
+
+	if _, err := db.Exec(query, version, target); err != nil {
+		return fmt.Errorf("failed to record cache %s for %s/%s: %w", column, version, target, err)
+	}
+	return nil
+}
+
+// GetCacheStats returns every (version,target) pair's download cache hit
+// rate, for GET /api/v1/cache/stats.
+func (db *DB) GetCacheStats() ([]BuildCacheStat, error) {
+	rows, err := db.Query(`SELECT version, target, hits, misses FROM build_cache_stats ORDER BY version, target`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query build cache stats: %w", err)
+	}
+	defer rows.Close()
+
+	var stats []BuildCacheStat
+	for rows.Next() {
+		var s BuildCacheStat
+		if err := rows.Scan(&s.Version, &s.Target, &s.Hits, &s.Misses); err != nil {
+			return nil, fmt.Errorf("failed to scan build cache stat: %w", err)
+		}
+		if total := s.Hits + s.Misses; total > 0 {
+			s.HitRate = float64(s.Hits) / float64(total)
+		}
+		stats = append(stats, s)
+	}
+	return stats, rows.Err()
+}
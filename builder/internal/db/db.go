@@ -4,8 +4,10 @@ import (
 	"database/sql"
 	"embed"
 	"fmt"
+	"log/slog"
 	"os"
 	"path/filepath"
+	"sort"
 
 	_ "modernc.org/sqlite"
 )
@@ -32,11 +34,27 @@ func NewDB(dbPath string) (*DB, error) {
 		return nil, fmt.Errorf("failed to open database: %w", err)
 	}
 
+	// database/sql pools connections, but PRAGMAs like busy_timeout are
+	// per-connection: a second pooled connection grabbed by a concurrent
+	// writer would never see it and would fail with an immediate
+	// SQLITE_BUSY instead of blocking briefly for its turn. Capping the pool
+	// to one connection makes every PRAGMA set below apply to every query,
+	// and is the standard way to drive SQLite through database/sql.
+	sqlDB.SetMaxOpenConns(1)
+
 	// Enable WAL mode for better concurrency
 	if _, err := sqlDB.Exec("PRAGMA journal_mode=WAL"); err != nil {
 		return nil, fmt.Errorf("failed to enable WAL mode: %w", err)
 	}
 
+	// With only one connection, busy_timeout mainly protects against
+	// external writers to the same file (e.g. another process), but it's
+	// cheap insurance: without it, any lock contention becomes an immediate
+	// SQLITE_BUSY instead of a brief, silent wait.
+	if _, err := sqlDB.Exec("PRAGMA busy_timeout=5000"); err != nil {
+		return nil, fmt.Errorf("failed to set busy timeout: %w", err)
+	}
+
 	// Enable foreign keys
 	if _, err := sqlDB.Exec("PRAGMA foreign_keys=ON"); err != nil {
 		return nil, fmt.Errorf("failed to enable foreign keys: %w", err)
@@ -52,17 +70,52 @@ func NewDB(dbPath string) (*DB, error) {
 	return db, nil
 }
 
-// runMigrations executes all migration files
+// runMigrations executes all migration files in order, skipping any that
+// have already been applied.
 func (db *DB) runMigrations() error {
-	// Read migration file
-	migrationSQL, err := migrationsFS.ReadFile("migrations/001_initial_schema.sql")
+	if _, err := db.Exec(`
+		CREATE TABLE IF NOT EXISTS schema_migrations (
+			filename   TEXT PRIMARY KEY,
+			applied_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+		)
+	`); err != nil {
+		return fmt.Errorf("failed to create schema_migrations table: %w", err)
+	}
+
+	entries, err := migrationsFS.ReadDir("migrations")
 	if err != nil {
-		return fmt.Errorf("failed to read migration file: %w", err)
+		return fmt.Errorf("failed to list migration files: %w", err)
 	}
 
-	// Execute migration
-	if _, err := db.Exec(string(migrationSQL)); err != nil {
-		return fmt.Errorf("failed to execute migration: %w", err)
+	names := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		names = append(names, entry.Name())
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		var applied int
+		if err := db.QueryRow("SELECT COUNT(*) FROM schema_migrations WHERE filename = ?", name).Scan(&applied); err != nil {
+			return fmt.Errorf("failed to check migration %s: %w", name, err)
+		}
+		if applied > 0 {
+			continue
+		}
+
+		migrationSQL, err := migrationsFS.ReadFile(filepath.Join("migrations", name))
+		if err != nil {
+			return fmt.Errorf("failed to read migration file %s: %w", name, err)
+		}
+
+		if _, err := db.Exec(string(migrationSQL)); err != nil {
+			return fmt.Errorf("failed to execute migration %s: %w", name, err)
+		}
+
+		if _, err := db.Exec("INSERT INTO schema_migrations (filename) VALUES (?)", name); err != nil {
+			return fmt.Errorf("failed to record migration %s: %w", name, err)
+		}
+
+		slog.Info("Applied database migration", "event_type", "migration_applied", "filename", name)
 	}
 
 	return nil
@@ -0,0 +1,51 @@
+package db
+
+import (
+	"fmt"
+	"time"
+)
+
+// RecordImagePull upserts imageTag's most recent successful pull time, for
+// the periodic image GC (see GetStaleImageTags) to judge staleness from.
+func (db *DB) RecordImagePull(imageTag string, pulledAt time.Time) error {
+	_, err := db.Exec(`
+		INSERT INTO image_pulls (image_tag, pulled_at)
+		VALUES (?, ?)
+		ON CONFLICT (image_tag) DO UPDATE SET pulled_at = excluded.pulled_at
+	`, imageTag, pulledAt)
+	if err != nil {
+		return fmt.Errorf("failed to record pull of %s: %w", imageTag, err)
+	}
+	return nil
+}
+
+// GetStaleImageTags returns the tags of every image last pulled before
+// cutoff, for the periodic image GC to remove from the container runtime.
+func (db *DB) GetStaleImageTags(cutoff time.Time) ([]string, error) {
+	rows, err := db.Query(`SELECT image_tag FROM image_pulls WHERE pulled_at < ?`, cutoff)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query stale image pulls: %w", err)
+	}
+	defer rows.Close()
+
+	var tags []string
+	for rows.Next() {
+		var tag string
+		if err := rows.Scan(&tag); err != nil {
+			return nil, fmt.Errorf("failed to scan image tag: %w", err)
+		}
+		tags = append(tags, tag)
+	}
+
+	return tags, rows.Err()
+}
+
+// DeleteImagePull removes imageTag's pulled_at record once its image has
+// been pruned by the GC.
+func (db *DB) DeleteImagePull(imageTag string) error {
+	_, err := db.Exec(`DELETE FROM image_pulls WHERE image_tag = ?`, imageTag)
+	if err != nil {
+		return fmt.Errorf("failed to delete image pull record for %s: %w", imageTag, err)
+	}
+	return nil
+}
@@ -0,0 +1,73 @@
+package db
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/aparcar/asu/builder/internal/models"
+)
+
+// UpsertPackageIndexCache records the package index most recently fetched
+// for (distro, version, target), along with the ETag to send on the next
+// conditional GET (see the pkgupdates package) and how many build_requests
+// rows for this target now have at least one outdated package.
+func (db *DB) UpsertPackageIndexCache(distro, version, target, etag string, packages map[string]string, staleRequestCount int) error {
+	packagesJSON, err := json.Marshal(packages)
+	if err != nil {
+		return fmt.Errorf("failed to marshal packages: %w", err)
+	}
+
+	_, err = db.Exec(`
+		INSERT INTO package_index_cache (distro, version, target, etag, packages_json, stale_request_count, last_checked_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT (distro, version, target) DO UPDATE SET
+			etag = excluded.etag,
+			packages_json = excluded.packages_json,
+			stale_request_count = excluded.stale_request_count,
+			last_checked_at = excluded.last_checked_at
+	`, distro, version, target, etag, packagesJSON, staleRequestCount, time.Now())
+	if err != nil {
+		return fmt.Errorf("failed to upsert package index cache: %w", err)
+	}
+
+	return nil
+}
+
+// GetPackageIndexCache returns the cached package index for (distro,
+// version, target), or (nil, nil) if it hasn't been fetched yet.
+func (db *DB) GetPackageIndexCache(distro, version, target string) (*models.PackageIndexCache, error) {
+	var c models.PackageIndexCache
+	var packagesJSON string
+
+	err := db.QueryRow(`
+		SELECT distro, version, target, etag, packages_json, stale_request_count, last_checked_at
+		FROM package_index_cache
+		WHERE distro = ? AND version = ? AND target = ?
+	`, distro, version, target).Scan(
+		&c.Distro, &c.Version, &c.Target, &c.ETag, &packagesJSON, &c.StaleRequestCount, &c.LastCheckedAt,
+	)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to query package index cache: %w", err)
+	}
+
+	if err := json.Unmarshal([]byte(packagesJSON), &c.Packages); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal packages: %w", err)
+	}
+
+	return &c, nil
+}
+
+// SumStalePackageRequests returns the total stale_request_count across every
+// cached target, for the stats page's "updates available" badge.
+func (db *DB) SumStalePackageRequests() (int, error) {
+	var total sql.NullInt64
+	if err := db.QueryRow(`SELECT SUM(stale_request_count) FROM package_index_cache`).Scan(&total); err != nil {
+		return 0, fmt.Errorf("failed to sum stale package requests: %w", err)
+	}
+	return int(total.Int64), nil
+}
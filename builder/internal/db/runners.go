@@ -0,0 +1,50 @@
+package db
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/aparcar/asu/builder/internal/models"
+)
+
+// UpsertRunnerHeartbeat records workerID's most recent heartbeat and the
+// build (if any) it is currently working on, so GET /api/v1/runners can
+// report which runner processes are alive.
+func (db *DB) UpsertRunnerHeartbeat(workerID, currentRequestHash string) error {
+	_, err := db.Exec(`
+		INSERT INTO runners (worker_id, last_heartbeat_at, current_request_hash)
+		VALUES (?, ?, ?)
+		ON CONFLICT (worker_id) DO UPDATE SET
+			last_heartbeat_at = excluded.last_heartbeat_at,
+			current_request_hash = excluded.current_request_hash
+	`, workerID, time.Now(), currentRequestHash)
+	if err != nil {
+		return fmt.Errorf("failed to record runner heartbeat for %s: %w", workerID, err)
+	}
+	return nil
+}
+
+// ListRunners returns every runner that has ever sent a heartbeat, most
+// recently active first.
+func (db *DB) ListRunners() ([]*models.Runner, error) {
+	rows, err := db.Query(`
+		SELECT worker_id, last_heartbeat_at, current_request_hash
+		FROM runners
+		ORDER BY last_heartbeat_at DESC
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query runners: %w", err)
+	}
+	defer rows.Close()
+
+	var runners []*models.Runner
+	for rows.Next() {
+		var r models.Runner
+		if err := rows.Scan(&r.WorkerID, &r.LastHeartbeatAt, &r.CurrentRequestHash); err != nil {
+			return nil, fmt.Errorf("failed to scan runner: %w", err)
+		}
+		runners = append(runners, &r)
+	}
+
+	return runners, rows.Err()
+}
@@ -28,9 +28,104 @@ func (db *DB) RecordBuildStat(stat *models.BuildStat) error {
 		return fmt.Errorf("failed to insert build stat: %w", err)
 	}
 
+	if stat.Resources != nil && stat.Resources.SampleCount > 0 {
+		if err := db.recordBuildMetrics(stat.Timestamp, stat.Version, stat.Target, stat.Profile, stat.Resources); err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
+// recordBuildMetrics inserts a build's resource usage profile into
+// build_metrics.
+func (db *DB) recordBuildMetrics(timestamp time.Time, version, target, profile string, resources *models.ResourceProfile) error {
+	query := `
+		INSERT INTO build_metrics (
+			timestamp, version, target, profile,
+			peak_cpu_percent, avg_cpu_percent, peak_mem_bytes, avg_mem_bytes,
+			block_read_bytes, block_write_bytes, net_rx_bytes, net_tx_bytes
+		)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`
+
+	_, err := db.Exec(query,
+		timestamp,
+		version,
+		target,
+		profile,
+		resources.PeakCPUPercent,
+		resources.AvgCPUPercent,
+		resources.PeakMemBytes,
+		resources.AvgMemBytes,
+		resources.BlockReadBytes,
+		resources.BlockWriteBytes,
+		resources.NetRxBytes,
+		resources.NetTxBytes,
+	)
+
+	if err != nil {
+		return fmt.Errorf("failed to insert build metrics: %w", err)
+	}
+
+	return nil
+}
+
+// BuildMetricsSummary aggregates build_metrics rows for a given
+// (version, target, profile), returned by GetBuildMetrics.
+type BuildMetricsSummary struct {
+	SampleCount        int     `json:"sample_count"`
+	AvgPeakCPUPercent  float64 `json:"avg_peak_cpu_percent"`
+	AvgCPUPercent      float64 `json:"avg_cpu_percent"`
+	AvgPeakMemBytes    float64 `json:"avg_peak_mem_bytes"`
+	AvgMemBytes        float64 `json:"avg_mem_bytes"`
+	AvgBlockReadBytes  float64 `json:"avg_block_read_bytes"`
+	AvgBlockWriteBytes float64 `json:"avg_block_write_bytes"`
+	AvgNetRxBytes      float64 `json:"avg_net_rx_bytes"`
+	AvgNetTxBytes      float64 `json:"avg_net_tx_bytes"`
+}
+
+// GetBuildMetrics returns the average resource usage figures recorded for
+// builds of (version, target, profile), or nil if none have been recorded.
+func (db *DB) GetBuildMetrics(version, target, profile string) (*BuildMetricsSummary, error) {
+	query := `
+		SELECT
+			COUNT(*),
+			COALESCE(AVG(peak_cpu_percent), 0),
+			COALESCE(AVG(avg_cpu_percent), 0),
+			COALESCE(AVG(peak_mem_bytes), 0),
+			COALESCE(AVG(avg_mem_bytes), 0),
+			COALESCE(AVG(block_read_bytes), 0),
+			COALESCE(AVG(block_write_bytes), 0),
+			COALESCE(AVG(net_rx_bytes), 0),
+			COALESCE(AVG(net_tx_bytes), 0)
+		FROM build_metrics
+		WHERE version = ? AND target = ? AND profile = ?
+	`
+
+	summary := &BuildMetricsSummary{}
+	err := db.QueryRow(query, version, target, profile).Scan(
+		&summary.SampleCount,
+		&summary.AvgPeakCPUPercent,
+		&summary.AvgCPUPercent,
+		&summary.AvgPeakMemBytes,
+		&summary.AvgMemBytes,
+		&summary.AvgBlockReadBytes,
+		&summary.AvgBlockWriteBytes,
+		&summary.AvgNetRxBytes,
+		&summary.AvgNetTxBytes,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query build metrics: %w", err)
+	}
+
+	if summary.SampleCount == 0 {
+		return nil, nil
+	}
+
+	return summary, nil
+}
+
 // GetBuildStatsPerDay returns build statistics grouped by day
 func (db *DB) GetBuildStatsPerDay(days int) (map[string]map[string]int, error) {
 	query := `
@@ -100,6 +195,38 @@ func (db *DB) GetBuildStatsByVersion(weeks int) (map[string]map[string]int, erro
 	return stats, rows.Err()
 }
 
+// GetRecentBuildTargets returns the `limit` most recently requested distinct
+// (version, target) pairs, most recent first. It's used to decide which
+// ImageBuilder images are worth prewarming.
+func (db *DB) GetRecentBuildTargets(limit int) ([]models.RecentBuildTarget, error) {
+	query := `
+		SELECT version, target, MAX(timestamp) as last_seen
+		FROM build_stats
+		WHERE version IS NOT NULL AND version != '' AND target IS NOT NULL AND target != ''
+		GROUP BY version, target
+		ORDER BY last_seen DESC
+		LIMIT ?
+	`
+
+	rows, err := db.Query(query, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query recent build targets: %w", err)
+	}
+	defer rows.Close()
+
+	var targets []models.RecentBuildTarget
+	for rows.Next() {
+		var t models.RecentBuildTarget
+		var lastSeen time.Time
+		if err := rows.Scan(&t.Version, &t.Target, &lastSeen); err != nil {
+			return nil, fmt.Errorf("failed to scan recent build target: %w", err)
+		}
+		targets = append(targets, t)
+	}
+
+	return targets, rows.Err()
+}
+
 // CleanOldStats removes statistics older than the specified number of days
 func (db *DB) CleanOldStats(daysToKeep int) error {
 	query := `DELETE FROM build_stats WHERE timestamp < datetime('now', '-' || ? || ' days')`
@@ -107,8 +234,10 @@ func (db *DB) CleanOldStats(daysToKeep int) error {
 	return err
 }
 
-// RecordEvent is a convenience function to record a build event
-func (db *DB) RecordEvent(eventType models.StatEventType, version, target, profile string, durationSecs int, diffPackages bool) error {
+// RecordEvent is a convenience function to record a build event.
+// resources may be nil; it's only meaningful for EventTypeBuildCompleted,
+// where it's recorded into build_metrics alongside the stat.
+func (db *DB) RecordEvent(eventType models.StatEventType, version, target, profile string, durationSecs int, diffPackages bool, resources *models.ResourceProfile) error {
 	stat := &models.BuildStat{
 		Timestamp:    time.Now(),
 		EventType:    eventType,
@@ -117,6 +246,7 @@ func (db *DB) RecordEvent(eventType models.StatEventType, version, target, profi
 		Profile:      profile,
 		DurationSecs: durationSecs,
 		DiffPackages: diffPackages,
+		Resources:    resources,
 	}
 	return db.RecordBuildStat(stat)
 }
@@ -0,0 +1,67 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/aparcar/asu/builder/internal/models"
+)
+
+// CreateUser inserts a new user account. passwordHash is expected to already
+// be an encoded argon2id hash (see auth.HashPassword) — this package stores
+// it verbatim and never hashes or verifies passwords itself.
+func (db *DB) CreateUser(username, passwordHash string, role models.Role) (*models.User, error) {
+	now := time.Now()
+	res, err := db.Exec(`
+		INSERT INTO users (username, password_hash, role, created_at)
+		VALUES (?, ?, ?, ?)
+	`, username, passwordHash, role, now)
+	if err != nil {
+		return nil, fmt.Errorf("failed to insert user: %w", err)
+	}
+
+	id, err := res.LastInsertId()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get inserted user id: %w", err)
+	}
+
+	return &models.User{
+		ID:           id,
+		Username:     username,
+		PasswordHash: passwordHash,
+		Role:         role,
+		CreatedAt:    now,
+	}, nil
+}
+
+// GetUserByUsername looks up a user by username, returning (nil, nil) if no
+// such user exists.
+func (db *DB) GetUserByUsername(username string) (*models.User, error) {
+	var u models.User
+	err := db.QueryRow(`
+		SELECT id, username, password_hash, role, created_at
+		FROM users
+		WHERE username = ?
+	`, username).Scan(&u.ID, &u.Username, &u.PasswordHash, &u.Role, &u.CreatedAt)
+
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to query user: %w", err)
+	}
+
+	return &u, nil
+}
+
+// UserCount returns the total number of registered users. Neither Register
+// nor CreateAdmin consults it: registration always creates "user"-role
+// accounts.
+func (db *DB) UserCount() (int, error) {
+	var count int
+	if err := db.QueryRow("SELECT COUNT(*) FROM users").Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to count users: %w", err)
+	}
+	return count, nil
+}
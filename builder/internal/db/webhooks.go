@@ -0,0 +1,94 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/aparcar/asu/builder/internal/models"
+)
+
+// CreateWebhookDelivery queues a new webhook delivery for eventType and
+// requestHash, due immediately.
+func (db *DB) CreateWebhookDelivery(eventType, requestHash, payload string) (int64, error) {
+	result, err := db.Exec(`
+		INSERT INTO webhook_deliveries (event_type, request_hash, payload, status, next_attempt_at)
+		VALUES (?, ?, ?, ?, ?)
+	`, eventType, requestHash, payload, models.WebhookStatusPending, time.Now())
+	if err != nil {
+		return 0, fmt.Errorf("failed to queue webhook delivery for %s: %w", requestHash, err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get webhook delivery id for %s: %w", requestHash, err)
+	}
+	return id, nil
+}
+
+// GetDueWebhookDeliveries returns pending deliveries whose next_attempt_at
+// has passed, oldest first, for the delivery loop to retry.
+func (db *DB) GetDueWebhookDeliveries() ([]models.WebhookDelivery, error) {
+	rows, err := db.Query(`
+		SELECT id, event_type, request_hash, payload, status, attempt_count, next_attempt_at, last_error, created_at, delivered_at
+		FROM webhook_deliveries
+		WHERE status = ? AND next_attempt_at <= ?
+		ORDER BY id ASC
+	`, models.WebhookStatusPending, time.Now())
+	if err != nil {
+		return nil, fmt.Errorf("failed to query due webhook deliveries: %w", err)
+	}
+	defer rows.Close()
+
+	var deliveries []models.WebhookDelivery
+	for rows.Next() {
+		var d models.WebhookDelivery
+		var deliveredAt sql.NullTime
+		if err := rows.Scan(&d.ID, &d.EventType, &d.RequestHash, &d.Payload, &d.Status, &d.AttemptCount, &d.NextAttemptAt, &d.LastError, &d.CreatedAt, &deliveredAt); err != nil {
+			return nil, fmt.Errorf("failed to scan webhook delivery: %w", err)
+		}
+		if deliveredAt.Valid {
+			d.DeliveredAt = &deliveredAt.Time
+		}
+		deliveries = append(deliveries, d)
+	}
+
+	return deliveries, rows.Err()
+}
+
+// MarkWebhookDelivered marks a delivery as successfully delivered.
+func (db *DB) MarkWebhookDelivered(id int64) error {
+	_, err := db.Exec(`
+		UPDATE webhook_deliveries SET status = ?, delivered_at = ? WHERE id = ?
+	`, models.WebhookStatusDelivered, time.Now(), id)
+	if err != nil {
+		return fmt.Errorf("failed to mark webhook delivery %d delivered: %w", id, err)
+	}
+	return nil
+}
+
+// MarkWebhookRetry records a failed delivery attempt and schedules
+// nextAttempt for another try.
+func (db *DB) MarkWebhookRetry(id int64, errMsg string, nextAttempt time.Time) error {
+	_, err := db.Exec(`
+		UPDATE webhook_deliveries
+		SET attempt_count = attempt_count + 1, last_error = ?, next_attempt_at = ?
+		WHERE id = ?
+	`, errMsg, nextAttempt, id)
+	if err != nil {
+		return fmt.Errorf("failed to record webhook retry for delivery %d: %w", id, err)
+	}
+	return nil
+}
+
+// MarkWebhookFailed marks a delivery as permanently failed: its retry
+// budget is exhausted, so the delivery loop won't pick it up again.
+func (db *DB) MarkWebhookFailed(id int64, errMsg string) error {
+	_, err := db.Exec(`
+		UPDATE webhook_deliveries SET status = ?, attempt_count = attempt_count + 1, last_error = ? WHERE id = ?
+	`, models.WebhookStatusFailed, errMsg, id)
+	if err != nil {
+		return fmt.Errorf("failed to mark webhook delivery %d failed: %w", id, err)
+	}
+	return nil
+}
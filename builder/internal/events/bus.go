@@ -0,0 +1,161 @@
+// Package events provides a builder-wide pub/sub bus for job and image
+// lifecycle events, independent of any single build's per-request log or
+// container-event streams (see api.eventStreamRegistry and logStreamRegistry
+// for those). It backs GET /api/v1/events and the webhooks package.
+package events
+
+import (
+	"encoding/json"
+	"log"
+	"sync"
+	"time"
+)
+
+// Type identifies the kind of event published to a Bus.
+type Type string
+
+const (
+	TypeJobEnqueued       Type = "job.enqueued"
+	TypeJobStarted        Type = "job.started"
+	TypeJobLog            Type = "job.log"
+	TypeJobCompleted      Type = "job.completed"
+	TypeJobFailed         Type = "job.failed"
+	TypeImagePullProgress Type = "image.pull.progress"
+)
+
+// Terminal reports whether t marks the end of a build, the point at which a
+// webhook delivery is triggered.
+func (t Type) Terminal() bool {
+	return t == TypeJobCompleted || t == TypeJobFailed
+}
+
+// Event is a single builder-wide lifecycle event published to a Bus.
+type Event struct {
+	Type        Type            `json:"type"`
+	RequestHash string          `json:"request_hash,omitempty"`
+	Timestamp   time.Time       `json:"time"`
+	Data        json.RawMessage `json:"data,omitempty"`
+}
+
+// Filter selects which published events a subscriber receives. A zero field
+// matches anything.
+type Filter struct {
+	Type        Type
+	RequestHash string
+	Since       time.Time
+}
+
+func (f Filter) matches(ev Event) bool {
+	if f.Type != "" && f.Type != ev.Type {
+		return false
+	}
+	if f.RequestHash != "" && f.RequestHash != ev.RequestHash {
+		return false
+	}
+	if !f.Since.IsZero() && ev.Timestamp.Before(f.Since) {
+		return false
+	}
+	return true
+}
+
+type subscriber struct {
+	ch     chan Event
+	filter Filter
+}
+
+// Bus fans published Events out to live subscribers (e.g. the
+// GET /api/v1/events SSE handler) and keeps a bounded in-memory history so a
+// subscriber can replay events it missed with a since= filter, modeled on
+// Podman's /events endpoint. Events aren't persisted to disk: a restart
+// drops history, same as Podman's.
+type Bus struct {
+	mu           sync.Mutex
+	subscribers  map[int]*subscriber
+	nextID       int
+	bufSize      int
+	history      []Event
+	historyLimit int
+}
+
+// NewBus creates a Bus whose subscriber channels buffer up to bufSize
+// events and whose replay history holds the most recent historyLimit
+// events.
+func NewBus(bufSize, historyLimit int) *Bus {
+	return &Bus{
+		subscribers:  make(map[int]*subscriber),
+		bufSize:      bufSize,
+		historyLimit: historyLimit,
+	}
+}
+
+// Publish fans ev out to every subscriber whose filter matches it and
+// appends it to the replay history. If ev.Timestamp is zero, it's set to
+// now.
+func (b *Bus) Publish(ev Event) {
+	if ev.Timestamp.IsZero() {
+		ev.Timestamp = time.Now()
+	}
+
+	b.mu.Lock()
+	b.history = append(b.history, ev)
+	if len(b.history) > b.historyLimit {
+		b.history = b.history[len(b.history)-b.historyLimit:]
+	}
+	subs := make([]*subscriber, 0, len(b.subscribers))
+	for _, s := range b.subscribers {
+		subs = append(subs, s)
+	}
+	b.mu.Unlock()
+
+	for _, s := range subs {
+		if !s.filter.matches(ev) {
+			continue
+		}
+		select {
+		case s.ch <- ev:
+		default:
+			log.Printf("events: dropping %s event, subscriber is not keeping up", ev.Type)
+		}
+	}
+}
+
+// Subscribe registers a new subscriber matching filter, returning its
+// channel and an id to later unsubscribe with.
+func (b *Bus) Subscribe(filter Filter) (int, <-chan Event) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	id := b.nextID
+	b.nextID++
+	ch := make(chan Event, b.bufSize)
+	b.subscribers[id] = &subscriber{ch: ch, filter: filter}
+	return id, ch
+}
+
+// Unsubscribe removes a subscriber registered with Subscribe and closes its
+// channel.
+func (b *Bus) Unsubscribe(id int) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if s, ok := b.subscribers[id]; ok {
+		delete(b.subscribers, id)
+		close(s.ch)
+	}
+}
+
+// Replay returns the retained history events matching filter, oldest
+// first, for a new subscriber to catch up on before switching to live
+// events.
+func (b *Bus) Replay(filter Filter) []Event {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	var matched []Event
+	for _, ev := range b.history {
+		if filter.matches(ev) {
+			matched = append(matched, ev)
+		}
+	}
+	return matched
+}
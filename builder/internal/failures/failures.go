@@ -0,0 +1,33 @@
+// Package failures classifies build failures as transient or permanent so
+// the queue knows whether a failed job is worth retrying.
+package failures
+
+import (
+	"regexp"
+
+	"github.com/aparcar/asu/builder/internal/models"
+)
+
+// transientPatterns match error messages known to be retryable blips
+// rather than something inherent to the request.
+var transientPatterns = []*regexp.Regexp{
+	regexp.MustCompile(`apk: .*: network error`),
+	regexp.MustCompile(`imagebuilder: Killed`),
+	regexp.MustCompile(`(?i)connection (refused|reset|timed out)`),
+	regexp.MustCompile(`(?i)temporary failure in name resolution`),
+	regexp.MustCompile(`(?i)i/o timeout`),
+	regexp.MustCompile(`(?i)context deadline exceeded`),
+}
+
+// Classify inspects errMsg for known transient-failure patterns. Anything
+// that doesn't match defaults to FailurePermanent: retrying a request that
+// can never succeed (bad profile, missing package) would just waste the
+// retry budget.
+func Classify(errMsg string) models.FailureClass {
+	for _, pattern := range transientPatterns {
+		if pattern.MatchString(errMsg) {
+			return models.FailureTransient
+		}
+	}
+	return models.FailurePermanent
+}
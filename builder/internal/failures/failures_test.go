@@ -0,0 +1,30 @@
+package failures
+
+import (
+	"testing"
+
+	"github.com/aparcar/asu/builder/internal/models"
+)
+
+func TestClassify(t *testing.T) {
+	cases := []struct {
+		errMsg string
+		want   models.FailureClass
+	}{
+		{"apk: fetch https://example.com: network error", models.FailureTransient},
+		{"imagebuilder: Killed", models.FailureTransient},
+		{"dial tcp: connection refused", models.FailureTransient},
+		{"lookup downloads.openwrt.org: Temporary failure in name resolution", models.FailureTransient},
+		{"read tcp 1.2.3.4:443: i/o timeout", models.FailureTransient},
+		{"context deadline exceeded", models.FailureTransient},
+		{"unknown package 'frobnicate'", models.FailurePermanent},
+		{"invalid profile for target", models.FailurePermanent},
+		{"", models.FailurePermanent},
+	}
+
+	for _, c := range cases {
+		if got := Classify(c.errMsg); got != c.want {
+			t.Errorf("Classify(%q) = %v, want %v", c.errMsg, got, c.want)
+		}
+	}
+}
@@ -0,0 +1,203 @@
+// Package jobqueue hands build scheduling off to a Redis-backed
+// github.com/hibiken/asynq queue instead of the ticker-driven polling loop
+// in the queue package, so multiple "serve" instances can share one queue
+// and get real retry backoff, per-target priorities, and a dead-letter
+// queue for free. An empty Config.Backend disables it, in which case New
+// returns (nil, nil) and queue.Worker's own polling loop is the only
+// scheduler, exactly as before this package existed.
+package jobqueue
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hibiken/asynq"
+)
+
+// TaskTypeBuild is the asynq task type for "run this build", handled by
+// whatever Handler RunServer is given (see queue.Worker.ProcessJobByHash).
+const TaskTypeBuild = "build:process"
+
+// queueDefault and queueCritical are the two asynq queues builds are
+// routed across; Config.HighPriorityTargets selects queueCritical.
+const (
+	queueDefault  = "default"
+	queueCritical = "critical"
+)
+
+// Config is the subset of config.Config the jobqueue package needs, kept
+// separate so this package doesn't import config (matches how the signer
+// and artifacts packages take their own narrow options structs).
+type Config struct {
+	Backend string // "" or "asynq"
+
+	RedisAddr     string
+	RedisPassword string
+	RedisDB       int
+
+	// HighPriorityTargets routes builds for these targets onto the
+	// "critical" queue instead of "default", so they're picked up ahead
+	// of the backlog.
+	HighPriorityTargets []string
+}
+
+// Queue enqueues build tasks and inspects queue state via asynq. A nil
+// *Queue (returned by New when disabled) is valid to call Enqueue/Stats on
+// from call sites that don't want to branch on whether the backend is
+// configured; see the nil receiver methods below.
+type Queue struct {
+	redisOpt  asynq.RedisClientOpt
+	client    *asynq.Client
+	inspector *asynq.Inspector
+
+	highPriorityTargets map[string]bool
+}
+
+// New returns a Queue backed by cfg.Backend, or nil if the asynq backend is
+// disabled (the default).
+func New(cfg Config) (*Queue, error) {
+	switch cfg.Backend {
+	case "":
+		return nil, nil
+	case "asynq":
+		redisOpt := asynq.RedisClientOpt{
+			Addr:     cfg.RedisAddr,
+			Password: cfg.RedisPassword,
+			DB:       cfg.RedisDB,
+		}
+
+		highPriority := make(map[string]bool, len(cfg.HighPriorityTargets))
+		for _, target := range cfg.HighPriorityTargets {
+			highPriority[target] = true
+		}
+
+		return &Queue{
+			redisOpt:            redisOpt,
+			client:              asynq.NewClient(redisOpt),
+			inspector:           asynq.NewInspector(redisOpt),
+			highPriorityTargets: highPriority,
+		}, nil
+	default:
+		return nil, fmt.Errorf("unknown job queue backend: %s", cfg.Backend)
+	}
+}
+
+// Close releases the underlying Redis connections.
+func (q *Queue) Close() error {
+	if q == nil {
+		return nil
+	}
+	if err := q.client.Close(); err != nil {
+		return err
+	}
+	return q.inspector.Close()
+}
+
+// Enqueue submits a build for requestHash to asynq, routed to the
+// "critical" queue if target is in Config.HighPriorityTargets and
+// "default" otherwise. The task ID is requestHash itself, so re-submitting
+// an identical request is a no-op rather than a duplicate task.
+func (q *Queue) Enqueue(requestHash, target string) error {
+	if q == nil {
+		return nil
+	}
+
+	task := asynq.NewTask(TaskTypeBuild, []byte(requestHash))
+
+	queueName := queueDefault
+	if q.highPriorityTargets[target] {
+		queueName = queueCritical
+	}
+
+	_, err := q.client.Enqueue(task, asynq.TaskID(requestHash), asynq.Queue(queueName))
+	if err != nil && err != asynq.ErrTaskIDConflict {
+		return fmt.Errorf("failed to enqueue build %s: %w", requestHash, err)
+	}
+	return nil
+}
+
+// Stats is the aggregate queue depth reported by GET /api/v1/queue/stats.
+type Stats struct {
+	Pending   int `json:"pending"`
+	Active    int `json:"active"`
+	Scheduled int `json:"scheduled"`
+	Retry     int `json:"retry"`
+	Archived  int `json:"archived"`
+	Completed int `json:"completed"`
+}
+
+// Stats aggregates queue depth across the default and critical queues.
+func (q *Queue) Stats() (*Stats, error) {
+	if q == nil {
+		return &Stats{}, nil
+	}
+
+	stats := &Stats{}
+	for _, queueName := range []string{queueDefault, queueCritical} {
+		info, err := q.inspector.GetQueueInfo(queueName)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get queue info for %s: %w", queueName, err)
+		}
+		stats.Pending += info.Pending
+		stats.Active += info.Active
+		stats.Scheduled += info.Scheduled
+		stats.Retry += info.Retry
+		stats.Archived += info.Archived
+		stats.Completed += info.Completed
+	}
+	return stats, nil
+}
+
+// Requeue moves an archived or retry-scheduled task back to pending
+// immediately, for the admin "retry now" action.
+func (q *Queue) Requeue(queueName, requestHash string) error {
+	if q == nil {
+		return fmt.Errorf("job queue backend is disabled")
+	}
+	return q.inspector.RunTask(queueName, requestHash)
+}
+
+// Kill permanently removes an archived task, for the admin "give up on
+// this build" action.
+func (q *Queue) Kill(queueName, requestHash string) error {
+	if q == nil {
+		return fmt.Errorf("job queue backend is disabled")
+	}
+	return q.inspector.DeleteTask(queueName, requestHash)
+}
+
+// Handler runs the build for requestHash, returning an error only if the
+// build should be retried by asynq (see queue.Worker.ProcessJobByHash,
+// which already applies this service's own db.RetryPolicy and only
+// reports an error upward when the job was left in a retryable state).
+type Handler func(ctx context.Context, requestHash string) error
+
+// RunServer runs an asynq server that dispatches TaskTypeBuild tasks to
+// handler until ctx is cancelled. It blocks, so call it from its own
+// goroutine the way queue.Worker.Start is called.
+func (q *Queue) RunServer(ctx context.Context, concurrency int, handler Handler) error {
+	if q == nil {
+		<-ctx.Done()
+		return nil
+	}
+
+	srv := asynq.NewServer(q.redisOpt, asynq.Config{
+		Concurrency: concurrency,
+		Queues: map[string]int{
+			queueCritical: 3,
+			queueDefault:  1,
+		},
+	})
+
+	mux := asynq.NewServeMux()
+	mux.HandleFunc(TaskTypeBuild, func(taskCtx context.Context, task *asynq.Task) error {
+		return handler(taskCtx, string(task.Payload()))
+	})
+
+	go func() {
+		<-ctx.Done()
+		srv.Shutdown()
+	}()
+
+	return srv.Run(mux)
+}
@@ -6,47 +6,153 @@ import "time"
 type JobStatus string
 
 const (
-	JobStatusPending   JobStatus = "pending"
-	JobStatusBuilding  JobStatus = "building"
-	JobStatusCompleted JobStatus = "completed"
-	JobStatusFailed    JobStatus = "failed"
+	JobStatusPending    JobStatus = "pending"
+	JobStatusBuilding   JobStatus = "building"
+	JobStatusCompleted  JobStatus = "completed"
+	JobStatusFailed     JobStatus = "failed"
+	JobStatusCancelling JobStatus = "cancelling"
+	JobStatusCancelled  JobStatus = "cancelled"
 )
 
 // BuildJob represents a build job in the queue
 type BuildJob struct {
-	ID            int64      `json:"id" db:"id"`
-	RequestHash   string     `json:"request_hash" db:"request_hash"`
-	Status        JobStatus  `json:"status" db:"status"`
-	StartedAt     *time.Time `json:"started_at,omitempty" db:"started_at"`
-	FinishedAt    *time.Time `json:"finished_at,omitempty" db:"finished_at"`
-	BuildCmd      string     `json:"build_cmd,omitempty" db:"build_cmd"`
-	Manifest      string     `json:"manifest,omitempty" db:"manifest"`
-	ErrorMessage  string     `json:"error_message,omitempty" db:"error_message"`
-	WorkerID      string     `json:"worker_id,omitempty" db:"worker_id"`
-	QueuePosition int        `json:"queue_position,omitempty" db:"queue_position"`
+	ID             int64      `json:"id" db:"id"`
+	RequestHash    string     `json:"request_hash" db:"request_hash"`
+	Status         JobStatus  `json:"status" db:"status"`
+	StartedAt      *time.Time `json:"started_at,omitempty" db:"started_at"`
+	FinishedAt     *time.Time `json:"finished_at,omitempty" db:"finished_at"`
+	BuildCmd       string     `json:"build_cmd,omitempty" db:"build_cmd"`
+	Manifest       string     `json:"manifest,omitempty" db:"manifest"`
+	ErrorMessage   string     `json:"error_message,omitempty" db:"error_message"`
+	WorkerID       string     `json:"worker_id,omitempty" db:"worker_id"`
+	QueuePosition  int        `json:"queue_position,omitempty" db:"queue_position"`
+	LeaseExpiresAt *time.Time `json:"lease_expires_at,omitempty" db:"lease_expires_at"`
+	AttemptCount   int        `json:"attempt_count" db:"attempt_count"`
+	NextAttemptAt  *time.Time `json:"next_attempt_at,omitempty" db:"next_attempt_at"`
+	LastErrorClass string     `json:"last_error_class,omitempty" db:"last_error_class"`
+}
+
+// FailureClass classifies why a build failed. A transient failure (network
+// blip, ImageBuilder OOM kill, worker timeout) is requeued for another
+// attempt; a permanent one (bad profile, missing package, manifest hash
+// mismatch) is not. See the failures package for classification.
+type FailureClass string
+
+const (
+	FailureTransient FailureClass = "transient"
+	FailurePermanent FailureClass = "permanent"
+)
+
+// BuildLogLine is a single line of worker build output, streamed to
+// subscribers over SSE and persisted (as a capped tail) to build_logs. Seq
+// is the line's monotonic sequence number, assigned on persist, so a
+// client can resume a subscription with ?since=<seq> without re-reading
+// lines it already has.
+type BuildLogLine struct {
+	Seq       int64     `json:"seq"`
+	Timestamp time.Time `json:"timestamp"`
+	Stream    string    `json:"stream"` // "stdout" or "stderr"
+	Text      string    `json:"text"`
+	Step      string    `json:"step,omitempty"` // build phase that produced this line, see StepName
+}
+
+// BuildContainerEvent is a single normalized container lifecycle event
+// (create, start, died, remove) observed for a build's container, streamed
+// to subscribers over SSE and persisted to build_container_events so
+// clients can see precise phase timings without polling build status.
+type BuildContainerEvent struct {
+	Timestamp time.Time `json:"timestamp"`
+	Type      string    `json:"type"`
+	Status    string    `json:"status"`
+}
+
+// StepName identifies one phase of a build. The set is deliberately open
+// (stored as a plain string) so new phases can be introduced without a
+// migration.
+type StepName string
+
+const (
+	StepDepsolve StepName = "depsolve"
+	StepImage    StepName = "image"
+	StepManifest StepName = "manifest"
+	StepSign     StepName = "sign"
+	StepPackage  StepName = "package"
+)
+
+// StepStatus represents the status of a single build step
+type StepStatus string
+
+const (
+	StepStatusRunning   StepStatus = "running"
+	StepStatusCompleted StepStatus = "completed"
+	StepStatusFailed    StepStatus = "failed"
+)
+
+// BuildStep is one phase of a build job's execution, persisted to
+// build_steps so progress can be rendered as a tree instead of a single
+// status/error_message blob.
+type BuildStep struct {
+	ID           int64      `json:"-" db:"id"`
+	JobID        int64      `json:"-" db:"job_id"`
+	Name         StepName   `json:"name" db:"name"`
+	Status       StepStatus `json:"status" db:"status"`
+	StartedAt    *time.Time `json:"started_at,omitempty" db:"started_at"`
+	FinishedAt   *time.Time `json:"finished_at,omitempty" db:"finished_at"`
+	ErrorMessage string     `json:"error_message,omitempty" db:"error_message"`
+	ExitCode     *int       `json:"exit_code,omitempty" db:"exit_code"`
+}
+
+// DurationSeconds returns how long the step ran, or 0 if it hasn't started
+// or finished yet.
+func (s BuildStep) DurationSeconds() int {
+	if s.StartedAt == nil || s.FinishedAt == nil {
+		return 0
+	}
+	return int(s.FinishedAt.Sub(*s.StartedAt).Seconds())
 }
 
 // BuildResult represents the result of a completed build
 type BuildResult struct {
-	RequestHash        string    `json:"request_hash" db:"request_hash"`
-	Images             string    `json:"images" db:"images"` // JSON array
-	Manifest           string    `json:"manifest" db:"manifest"`
-	BuildAt            time.Time `json:"build_at" db:"build_at"`
-	CacheHit           bool      `json:"cache_hit" db:"cache_hit"`
-	BuildDurationSecs  int       `json:"build_duration_seconds" db:"build_duration_seconds"`
+	RequestHash           string    `json:"request_hash" db:"request_hash"`
+	Images                string    `json:"images" db:"images"` // JSON array
+	Manifest              string    `json:"manifest" db:"manifest"`
+	BuildAt               time.Time `json:"build_at" db:"build_at"`
+	CacheHit              bool      `json:"cache_hit" db:"cache_hit"`
+	BuildDurationSecs     int       `json:"build_duration_seconds" db:"build_duration_seconds"`
+	Signatures            string    `json:"signatures,omitempty" db:"signatures"` // JSON map of artifact -> {part: signature path}
+	SigningKeyFingerprint string    `json:"signing_key_fingerprint,omitempty" db:"signing_key_fingerprint"`
+	ArtifactURLs          string    `json:"artifact_urls,omitempty" db:"artifact_urls"` // JSON map of artifact -> remote store key (not a URL: presigned URLs expire, so api.Server re-presigns these on every read, see the artifacts package)
 }
 
 // BuildResponse is the API response for build requests
 type BuildResponse struct {
-	RequestHash    string     `json:"request_hash"`
-	Status         JobStatus  `json:"status"`
-	QueuePosition  int        `json:"queue_position,omitempty"`
-	Images         []string   `json:"images,omitempty"`
-	Manifest       string     `json:"manifest,omitempty"`
-	ErrorMessage   string     `json:"error_message,omitempty"`
-	BuildDuration  int        `json:"build_duration,omitempty"`
-	EnqueuedAt     *time.Time `json:"enqueued_at,omitempty"`
-	StartedAt      *time.Time `json:"started_at,omitempty"`
-	FinishedAt     *time.Time `json:"finished_at,omitempty"`
-	CacheHit       bool       `json:"cache_hit,omitempty"`
+	RequestHash           string            `json:"request_hash"`
+	Status                JobStatus         `json:"status"`
+	QueuePosition         int               `json:"queue_position,omitempty"`
+	Images                []string          `json:"images,omitempty"`
+	Manifest              string            `json:"manifest,omitempty"`
+	ErrorMessage          string            `json:"error_message,omitempty"`
+	FailedStep            *StepError        `json:"failed_step,omitempty"`
+	BuildDuration         int               `json:"build_duration,omitempty"`
+	EnqueuedAt            *time.Time        `json:"enqueued_at,omitempty"`
+	StartedAt             *time.Time        `json:"started_at,omitempty"`
+	FinishedAt            *time.Time        `json:"finished_at,omitempty"`
+	CacheHit              bool              `json:"cache_hit,omitempty"`
+	LeaseExpiresAt        *time.Time        `json:"lease_expires_at,omitempty"`
+	AttemptCount          int               `json:"attempt_count,omitempty"`
+	NextAttemptAt         *time.Time        `json:"next_attempt_at,omitempty"`
+	Logs                  []BuildLogLine    `json:"logs,omitempty"`
+	Steps                 []BuildStep       `json:"steps,omitempty"`
+	Signatures            string            `json:"signatures,omitempty"`
+	SigningKeyFingerprint string            `json:"signing_key_fingerprint,omitempty"`
+	ArtifactURLs          map[string]string `json:"artifact_urls,omitempty"`
+}
+
+// StepError points a failed build response at the specific step that
+// caused it, instead of leaving the client to guess from a flat
+// error_message which phase actually failed.
+type StepError struct {
+	Step         StepName `json:"step"`
+	ErrorMessage string   `json:"error_message"`
+	ExitCode     *int     `json:"exit_code,omitempty"`
 }
@@ -27,6 +27,16 @@ type BuildRequest struct {
 	CreatedAt        time.Time         `json:"created_at" db:"created_at"`
 }
 
+// BuildTarget is a (Distro, Version, Target) combination, the granularity
+// at which upstream package index metadata is fetched and cached (see the
+// pkgupdates package) — distinct profiles and package sets of the same
+// target all share one index.
+type BuildTarget struct {
+	Distro  string `db:"distro"`
+	Version string `db:"version"`
+	Target  string `db:"target"`
+}
+
 // ComputeHash calculates the deterministic hash for this build request
 func (br *BuildRequest) ComputeHash() string {
 	// Normalize and sort packages for consistent hashing
@@ -0,0 +1,24 @@
+package models
+
+import "time"
+
+// PackageIndexCache is the last upstream package index fetched for one
+// (Distro, Version, Target) combination (see the pkgupdates package).
+// Packages maps package name to the newest version the index advertised.
+type PackageIndexCache struct {
+	Distro            string            `json:"distro" db:"distro"`
+	Version           string            `json:"version" db:"version"`
+	Target            string            `json:"target" db:"target"`
+	ETag              string            `json:"etag" db:"etag"`
+	Packages          map[string]string `json:"packages"`
+	StaleRequestCount int               `json:"stale_request_count" db:"stale_request_count"`
+	LastCheckedAt     time.Time         `json:"last_checked_at" db:"last_checked_at"`
+}
+
+// PackageUpdate describes one package whose upstream version has moved past
+// the version a build was made with.
+type PackageUpdate struct {
+	Package        string `json:"package"`
+	CurrentVersion string `json:"current_version"`
+	NewVersion     string `json:"new_version"`
+}
@@ -0,0 +1,13 @@
+package models
+
+import "time"
+
+// Runner is a build-execution process that has registered with the server
+// by sending at least one heartbeat (see db.UpsertRunnerHeartbeat). It is
+// reported at GET /api/v1/runners so operators can see which "runner"
+// processes (cmd/runner) are alive and what they are currently building.
+type Runner struct {
+	WorkerID           string    `json:"worker_id" db:"worker_id"`
+	LastHeartbeatAt    time.Time `json:"last_heartbeat_at" db:"last_heartbeat_at"`
+	CurrentRequestHash string    `json:"current_request_hash,omitempty" db:"current_request_hash"`
+}
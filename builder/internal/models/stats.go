@@ -10,16 +10,41 @@ const (
 	EventTypeCacheHit       StatEventType = "cache_hit"
 	EventTypeFailure        StatEventType = "failure"
 	EventTypeBuildCompleted StatEventType = "build_completed"
+	EventTypeCancelled      StatEventType = "cancelled"
 )
 
+// RecentBuildTarget is a distinct (version, target) pair recently built,
+// used to decide which ImageBuilder images to prewarm.
+type RecentBuildTarget struct {
+	Version string
+	Target  string
+}
+
 // BuildStat represents a statistical event
 type BuildStat struct {
-	ID            int64         `json:"id" db:"id"`
-	Timestamp     time.Time     `json:"timestamp" db:"timestamp"`
-	EventType     StatEventType `json:"event_type" db:"event_type"`
-	Version       string        `json:"version,omitempty" db:"version"`
-	Target        string        `json:"target,omitempty" db:"target"`
-	Profile       string        `json:"profile,omitempty" db:"profile"`
-	DurationSecs  int           `json:"duration_seconds,omitempty" db:"duration_seconds"`
-	DiffPackages  bool          `json:"diff_packages" db:"diff_packages"`
+	ID           int64            `json:"id" db:"id"`
+	Timestamp    time.Time        `json:"timestamp" db:"timestamp"`
+	EventType    StatEventType    `json:"event_type" db:"event_type"`
+	Version      string           `json:"version,omitempty" db:"version"`
+	Target       string           `json:"target,omitempty" db:"target"`
+	Profile      string           `json:"profile,omitempty" db:"profile"`
+	DurationSecs int              `json:"duration_seconds,omitempty" db:"duration_seconds"`
+	DiffPackages bool             `json:"diff_packages" db:"diff_packages"`
+	Resources    *ResourceProfile `json:"resources,omitempty" db:"-"`
+}
+
+// ResourceProfile summarizes a build container's CPU, memory, and I/O usage
+// over its lifetime, recorded into build_metrics alongside a BuildStat.
+// SampleCount is 0 if the container exited before a single poll landed, in
+// which case it carries no real data and RecordBuildStat skips it.
+type ResourceProfile struct {
+	SampleCount     int     `json:"sample_count"`
+	PeakCPUPercent  float64 `json:"peak_cpu_percent"`
+	AvgCPUPercent   float64 `json:"avg_cpu_percent"`
+	PeakMemBytes    uint64  `json:"peak_mem_bytes"`
+	AvgMemBytes     uint64  `json:"avg_mem_bytes"`
+	BlockReadBytes  uint64  `json:"block_read_bytes"`
+	BlockWriteBytes uint64  `json:"block_write_bytes"`
+	NetRxBytes      uint64  `json:"net_rx_bytes"`
+	NetTxBytes      uint64  `json:"net_tx_bytes"`
 }
@@ -0,0 +1,25 @@
+package models
+
+import "time"
+
+// Role gates access to admin-only routes (GET /config, the queue admin
+// endpoints) versus routes any authenticated user can call (POST /build and
+// viewing their own build history, tracked by BuildRequest.Client).
+type Role string
+
+const (
+	RoleAdmin Role = "admin"
+	RoleUser  Role = "user"
+)
+
+// User is an account stored in the users table (see the auth package for
+// how PasswordHash is produced and verified). PasswordHash is never
+// serialized to JSON so it can't leak through any response that happens to
+// embed a User.
+type User struct {
+	ID           int64     `json:"id" db:"id"`
+	Username     string    `json:"username" db:"username"`
+	PasswordHash string    `json:"-" db:"password_hash"`
+	Role         Role      `json:"role" db:"role"`
+	CreatedAt    time.Time `json:"created_at" db:"created_at"`
+}
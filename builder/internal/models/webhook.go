@@ -0,0 +1,27 @@
+package models
+
+import "time"
+
+// WebhookDeliveryStatus represents the delivery state of a WebhookDelivery.
+type WebhookDeliveryStatus string
+
+const (
+	WebhookStatusPending   WebhookDeliveryStatus = "pending"
+	WebhookStatusDelivered WebhookDeliveryStatus = "delivered"
+	WebhookStatusFailed    WebhookDeliveryStatus = "failed" // retry budget exhausted
+)
+
+// WebhookDelivery is one queued or attempted POST of a terminal build event
+// to config.Config.WebhookURL (see the webhooks package).
+type WebhookDelivery struct {
+	ID            int64                 `json:"id" db:"id"`
+	EventType     string                `json:"event_type" db:"event_type"`
+	RequestHash   string                `json:"request_hash" db:"request_hash"`
+	Payload       string                `json:"payload" db:"payload"` // JSON body posted to WebhookURL
+	Status        WebhookDeliveryStatus `json:"status" db:"status"`
+	AttemptCount  int                   `json:"attempt_count" db:"attempt_count"`
+	NextAttemptAt time.Time             `json:"next_attempt_at" db:"next_attempt_at"`
+	LastError     string                `json:"last_error,omitempty" db:"last_error"`
+	CreatedAt     time.Time             `json:"created_at" db:"created_at"`
+	DeliveredAt   *time.Time            `json:"delivered_at,omitempty" db:"delivered_at"`
+}
@@ -0,0 +1,86 @@
+package pkgupdates
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/aparcar/asu/builder/internal/models"
+)
+
+// fetchIndex downloads the opkg Packages index for target, sending
+// prevETag as If-None-Match so an unchanged index costs a 304 instead of a
+// full re-download. notModified is true only on a 304 response, in which
+// case packages is nil and the caller should keep using its previously
+// cached index.
+func (c *Checker) fetchIndex(ctx context.Context, target models.BuildTarget, prevETag string) (packages map[string]string, etag string, notModified bool, err error) {
+	url := fmt.Sprintf("%s/releases/%s/targets/%s/packages/Packages", c.upstreamURL, target.Version, target.Target)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, "", false, fmt.Errorf("failed to create request: %w", err)
+	}
+	if prevETag != "" {
+		req.Header.Set("If-None-Match", prevETag)
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, "", false, fmt.Errorf("failed to fetch %s: %w", url, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotModified {
+		return nil, prevETag, true, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, "", false, fmt.Errorf("unexpected status %d fetching %s", resp.StatusCode, url)
+	}
+
+	packages, err = parsePackagesIndex(resp.Body)
+	if err != nil {
+		return nil, "", false, fmt.Errorf("failed to parse package index: %w", err)
+	}
+
+	return packages, resp.Header.Get("ETag"), false, nil
+}
+
+// parsePackagesIndex parses an opkg "Packages" index: RFC 822-style
+// stanzas, one per package, separated by a blank line, each with at least
+// "Package:" and "Version:" fields.
+func parsePackagesIndex(r io.Reader) (map[string]string, error) {
+	packages := make(map[string]string)
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+
+	var name, version string
+	for scanner.Scan() {
+		line := scanner.Text()
+
+		switch {
+		case line == "":
+			if name != "" && version != "" {
+				packages[name] = version
+			}
+			name, version = "", ""
+		case strings.HasPrefix(line, "Package: "):
+			name = strings.TrimPrefix(line, "Package: ")
+		case strings.HasPrefix(line, "Version: "):
+			version = strings.TrimPrefix(line, "Version: ")
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to scan index: %w", err)
+	}
+
+	// The index doesn't necessarily end with a trailing blank line.
+	if name != "" && version != "" {
+		packages[name] = version
+	}
+
+	return packages, nil
+}
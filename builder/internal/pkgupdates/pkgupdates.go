@@ -0,0 +1,182 @@
+// Package pkgupdates periodically fetches upstream OpenWrt package index
+// metadata for every (distro, version, target) seen in build_requests,
+// caches it (see db.UpsertPackageIndexCache), and flags build requests whose
+// PackagesVersions have fallen behind — "Dependabot for cached builds". When
+// config.AutoRebuildOnUpdate is set, it also re-enqueues a fresh build for
+// each stale request, reusing BuildRequest.ComputeHash the same way
+// handleBuildRequest does so the rebuild dedupes against any identical
+// request already in flight.
+package pkgupdates
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/aparcar/asu/builder/internal/db"
+	"github.com/aparcar/asu/builder/internal/jobqueue"
+	"github.com/aparcar/asu/builder/internal/models"
+	"github.com/aparcar/asu/builder/internal/queue"
+)
+
+// Checker fetches and caches upstream package indexes and, if configured,
+// auto-rebuilds stale build requests.
+type Checker struct {
+	db          *db.DB
+	upstreamURL string
+	jobQueue    *jobqueue.Queue // may be nil; EnqueueJob tolerates that
+	autoRebuild bool
+	client      *http.Client
+	logger      *slog.Logger
+}
+
+// New returns a Checker that fetches indexes from upstreamURL (the same
+// base URL build images are downloaded from). jobQueue may be nil.
+func New(database *db.DB, upstreamURL string, jobQueue *jobqueue.Queue, autoRebuild bool) *Checker {
+	return &Checker{
+		db:          database,
+		upstreamURL: upstreamURL,
+		jobQueue:    jobQueue,
+		autoRebuild: autoRebuild,
+		client:      &http.Client{Timeout: 30 * time.Second},
+		logger:      slog.Default(),
+	}
+}
+
+// Start checks every distinct build target on each tick of interval. It
+// blocks until ctx is cancelled, so callers should run it in a goroutine.
+func (c *Checker) Start(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	c.logger.Info("Package update checker started", "event_type", "pkgupdates_started", "interval", interval.String())
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := c.CheckAll(ctx); err != nil {
+				c.logger.Error("Package update check failed", "event_type", "pkgupdates_check_failed", "error", err)
+			}
+		}
+	}
+}
+
+// CheckAll refreshes the cached package index for every distinct build
+// target and, if c.autoRebuild, enqueues a fresh build for every stale
+// request found.
+func (c *Checker) CheckAll(ctx context.Context) error {
+	targets, err := c.db.ListDistinctBuildTargets()
+	if err != nil {
+		return fmt.Errorf("failed to list build targets: %w", err)
+	}
+
+	for _, target := range targets {
+		if err := c.checkTarget(ctx, target); err != nil {
+			c.logger.Error("Failed to check target for package updates", "distro", target.Distro, "version", target.Version, "target", target.Target, "error", err)
+		}
+	}
+
+	return nil
+}
+
+func (c *Checker) checkTarget(ctx context.Context, target models.BuildTarget) error {
+	cached, err := c.db.GetPackageIndexCache(target.Distro, target.Version, target.Target)
+	if err != nil {
+		return fmt.Errorf("failed to load cached index: %w", err)
+	}
+
+	prevETag := ""
+	if cached != nil {
+		prevETag = cached.ETag
+	}
+
+	packages, etag, notModified, err := c.fetchIndex(ctx, target, prevETag)
+	if err != nil {
+		return fmt.Errorf("failed to fetch index: %w", err)
+	}
+
+	if notModified {
+		// Index didn't change, but build_requests may have gained new
+		// stale rows since the last check (a build submitted with
+		// already-outdated packages), so stale_request_count is still
+		// recomputed against the previously cached packages.
+		packages = cached.Packages
+	}
+
+	requests, err := c.db.ListBuildRequestsForTarget(target.Distro, target.Version, target.Target)
+	if err != nil {
+		return fmt.Errorf("failed to list build requests: %w", err)
+	}
+
+	staleCount := 0
+	for _, req := range requests {
+		updates := Outdated(req.PackagesVersions, packages)
+		if len(updates) == 0 {
+			continue
+		}
+		staleCount++
+
+		if c.autoRebuild {
+			if err := c.rebuild(ctx, req, packages); err != nil {
+				c.logger.Error("Failed to auto-rebuild stale request", "request_hash", req.RequestHash, "error", err)
+			}
+		}
+	}
+
+	if err := c.db.UpsertPackageIndexCache(target.Distro, target.Version, target.Target, etag, packages, staleCount); err != nil {
+		return fmt.Errorf("failed to cache index: %w", err)
+	}
+
+	return nil
+}
+
+// rebuild re-enqueues req with its PackagesVersions updated to the newest
+// versions in packages, reusing ComputeHash so an identical rebuild already
+// in flight or already cached is deduplicated the same way a fresh client
+// submission would be.
+func (c *Checker) rebuild(ctx context.Context, req *models.BuildRequest, packages map[string]string) error {
+	updated := *req
+	updated.PackagesVersions = mergeVersions(req.PackagesVersions, packages)
+	updated.CreatedAt = time.Now()
+	updated.RequestHash = updated.ComputeHash()
+
+	exists, err := c.db.BuildRequestExists(updated.RequestHash)
+	if err != nil {
+		return fmt.Errorf("failed to check request existence: %w", err)
+	}
+	if !exists {
+		if err := c.db.CreateBuildRequest(ctx, &updated); err != nil {
+			return fmt.Errorf("failed to save rebuild request: %w", err)
+		}
+	}
+
+	if err := queue.EnqueueJob(ctx, c.db, c.jobQueue, &updated); err != nil {
+		return fmt.Errorf("failed to enqueue rebuild: %w", err)
+	}
+
+	c.logger.Info("Auto-enqueued rebuild for stale packages",
+		"event_type", "pkgupdates_auto_rebuild",
+		"original_request_hash", req.RequestHash,
+		"new_request_hash", updated.RequestHash,
+	)
+	return nil
+}
+
+// mergeVersions returns a copy of pinned with every package also present in
+// latest bumped to latest's version, leaving packages pinned doesn't
+// mention untouched.
+func mergeVersions(pinned, latest map[string]string) map[string]string {
+	merged := make(map[string]string, len(pinned))
+	for pkg, version := range pinned {
+		if newVersion, ok := latest[pkg]; ok {
+			merged[pkg] = newVersion
+			continue
+		}
+		merged[pkg] = version
+	}
+	return merged
+}
@@ -0,0 +1,35 @@
+package pkgupdates
+
+import (
+	"sort"
+
+	"github.com/aparcar/asu/builder/internal/models"
+)
+
+// Outdated compares a build's pinned package versions against the latest
+// index and returns every package whose pinned version is older than the
+// index's, per opkg/dpkg version ordering (see compareVersions) rather
+// than a plain string mismatch — a mirror lag or a pinned version ahead
+// of a freshly-fetched index must not be reported as an available
+// update. Results are sorted by package name for a stable response/log
+// order. A package the build didn't pin a version for (PackagesVersions
+// only records pins, not every package in the image) isn't reported —
+// there's nothing to compare it against.
+func Outdated(pinned, latest map[string]string) []models.PackageUpdate {
+	var updates []models.PackageUpdate
+
+	for pkg, currentVersion := range pinned {
+		newVersion, ok := latest[pkg]
+		if !ok || compareVersions(newVersion, currentVersion) <= 0 {
+			continue
+		}
+		updates = append(updates, models.PackageUpdate{
+			Package:        pkg,
+			CurrentVersion: currentVersion,
+			NewVersion:     newVersion,
+		})
+	}
+
+	sort.Slice(updates, func(i, j int) bool { return updates[i].Package < updates[j].Package })
+	return updates
+}
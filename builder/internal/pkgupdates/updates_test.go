@@ -0,0 +1,26 @@
+package pkgupdates
+
+import "testing"
+
+func TestOutdated(t *testing.T) {
+	pinned := map[string]string{
+		"curl":    "8.1.0-1",
+		"openssl": "3.0.5",
+		"busybox": "1.36.1",
+		"jq":      "1.7.0",
+	}
+	latest := map[string]string{
+		"curl":    "8.1.0-2", // newer revision
+		"openssl": "3.0.1",   // older than pinned: not an update
+		"busybox": "1.36.1",  // unchanged
+		// jq absent from the index: not reported
+	}
+
+	updates := Outdated(pinned, latest)
+	if len(updates) != 1 {
+		t.Fatalf("Outdated returned %d updates, want 1: %+v", len(updates), updates)
+	}
+	if updates[0].Package != "curl" || updates[0].NewVersion != "8.1.0-2" {
+		t.Errorf("Outdated returned %+v, want curl -> 8.1.0-2", updates[0])
+	}
+}
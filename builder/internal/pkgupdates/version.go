@@ -0,0 +1,125 @@
+package pkgupdates
+
+import "strings"
+
+// compareVersions compares two opkg/dpkg-style version strings
+// ([epoch:]upstream_version[-revision]) and returns a negative number if a
+// sorts before b, zero if they're equal, and a positive number if a sorts
+// after b. It implements the comparison Debian policy §5.6.12 (and opkg,
+// which borrows it) specifies: epoch compares numerically, then the
+// upstream and revision parts each compare as alternating runs of
+// non-digit and digit characters, with non-digit runs compared specially
+// so that "~" sorts before everything, including the end of the string.
+func compareVersions(a, b string) int {
+	ea, ua, ra := splitVersion(a)
+	eb, ub, rb := splitVersion(b)
+
+	if c := ea - eb; c != 0 {
+		return c
+	}
+	if c := compareVersionPart(ua, ub); c != 0 {
+		return c
+	}
+	return compareVersionPart(ra, rb)
+}
+
+// splitVersion splits v into its epoch (0 if absent), upstream version,
+// and revision (empty if absent) components.
+func splitVersion(v string) (epoch int, upstream, revision string) {
+	if idx := strings.IndexByte(v, ':'); idx >= 0 {
+		for _, c := range v[:idx] {
+			if c < '0' || c > '9' {
+				epoch = 0
+				break
+			}
+			epoch = epoch*10 + int(c-'0')
+		}
+		v = v[idx+1:]
+	}
+
+	if idx := strings.LastIndexByte(v, '-'); idx >= 0 {
+		return epoch, v[:idx], v[idx+1:]
+	}
+	return epoch, v, ""
+}
+
+// compareVersionPart compares one upstream-or-revision part of two
+// versions by walking matched runs of non-digit then digit characters.
+func compareVersionPart(a, b string) int {
+	for len(a) > 0 || len(b) > 0 {
+		aAlpha, aRest := nonDigitRun(a)
+		bAlpha, bRest := nonDigitRun(b)
+		if c := compareNonDigitRun(aAlpha, bAlpha); c != 0 {
+			return c
+		}
+		a, b = aRest, bRest
+
+		aNum, aRest := digitRun(a)
+		bNum, bRest := digitRun(b)
+		if c := compareNumericRun(aNum, bNum); c != 0 {
+			return c
+		}
+		a, b = aRest, bRest
+	}
+	return 0
+}
+
+func nonDigitRun(s string) (run, rest string) {
+	i := 0
+	for i < len(s) && (s[i] < '0' || s[i] > '9') {
+		i++
+	}
+	return s[:i], s[i:]
+}
+
+func digitRun(s string) (run, rest string) {
+	i := 0
+	for i < len(s) && s[i] >= '0' && s[i] <= '9' {
+		i++
+	}
+	return s[:i], s[i:]
+}
+
+// compareNonDigitRun compares two non-digit runs character by character
+// using charOrder, so "~" sorts before the end of a run, which sorts
+// before a letter, which sorts before any other character.
+func compareNonDigitRun(a, b string) int {
+	for i := 0; i < len(a) || i < len(b); i++ {
+		var ca, cb byte
+		if i < len(a) {
+			ca = a[i]
+		}
+		if i < len(b) {
+			cb = b[i]
+		}
+		if ca == cb {
+			continue
+		}
+		return charOrder(ca) - charOrder(cb)
+	}
+	return 0
+}
+
+func charOrder(c byte) int {
+	switch {
+	case c == '~':
+		return -1
+	case c == 0:
+		return 0
+	case c >= 'A' && c <= 'Z' || c >= 'a' && c <= 'z':
+		return int(c)
+	default:
+		return int(c) + 256
+	}
+}
+
+// compareNumericRun compares two runs of digits (or empty, treated as 0)
+// as plain integers, ignoring leading zeros.
+func compareNumericRun(a, b string) int {
+	a = strings.TrimLeft(a, "0")
+	b = strings.TrimLeft(b, "0")
+	if len(a) != len(b) {
+		return len(a) - len(b)
+	}
+	return strings.Compare(a, b)
+}
@@ -0,0 +1,51 @@
+package pkgupdates
+
+import "testing"
+
+func TestCompareVersions(t *testing.T) {
+	cases := []struct {
+		a, b string
+		want int // -1, 0, or 1, per sign of compareVersions
+	}{
+		{"1.2.3", "1.2.3", 0},
+		{"1.2.3", "1.2.4", -1},
+		{"1.2.4", "1.2.3", 1},
+		{"1.2.3-1", "1.2.3-2", -1},
+		{"1.2.10", "1.2.9", 1},
+		{"1:1.0", "2.0", 1},      // epoch outranks upstream version
+		{"1.0~rc1", "1.0", -1},   // ~ sorts before the end of the string
+		{"1.0", "1.0.1", -1},     // shorter run is "less" once digits diverge
+		{"2024-01-01", "2024-01-02", -1},
+	}
+
+	for _, c := range cases {
+		got := sign(compareVersions(c.a, c.b))
+		if got != c.want {
+			t.Errorf("compareVersions(%q, %q) = %d, want sign %d", c.a, c.b, got, c.want)
+		}
+	}
+}
+
+func TestCompareVersionsAntisymmetric(t *testing.T) {
+	pairs := [][2]string{
+		{"1.2.3", "1.2.4"},
+		{"1:1.0", "2.0"},
+		{"1.0~rc1", "1.0"},
+	}
+	for _, p := range pairs {
+		if sign(compareVersions(p[0], p[1])) != -sign(compareVersions(p[1], p[0])) {
+			t.Errorf("compareVersions(%q, %q) and the reverse call aren't antisymmetric", p[0], p[1])
+		}
+	}
+}
+
+func sign(n int) int {
+	switch {
+	case n < 0:
+		return -1
+	case n > 0:
+		return 1
+	default:
+		return 0
+	}
+}
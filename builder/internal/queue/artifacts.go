@@ -0,0 +1,57 @@
+package queue
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/aparcar/asu/builder/internal/artifacts"
+)
+
+// uploadArtifacts pushes every file in images plus manifest from dir to
+// store under "<requestHash>/<filename>", mirroring signArtifacts' own
+// per-file loop. It returns a JSON-encoded map of artifact -> store key for
+// models.BuildResult.ArtifactURLs. Keys, not the presigned URLs Upload
+// returns, are what get persisted: a presigned URL expires, so
+// api.Server.handleBuildStatus re-presigns each key into a fresh URL on
+// every read instead of serving back whatever was valid at upload time.
+func uploadArtifacts(ctx context.Context, store artifacts.Store, dir, requestHash, manifest string, images []string) (string, error) {
+	keys := make(map[string]string, len(images)+1)
+
+	for _, image := range images {
+		f, err := os.Open(filepath.Join(dir, image))
+		if err != nil {
+			return "", fmt.Errorf("failed to open %s for upload: %w", image, err)
+		}
+		info, err := f.Stat()
+		if err != nil {
+			f.Close()
+			return "", fmt.Errorf("failed to stat %s for upload: %w", image, err)
+		}
+		key := filepath.Join(requestHash, image)
+		_, err = store.Upload(ctx, key, f, info.Size())
+		f.Close()
+		if err != nil {
+			return "", fmt.Errorf("failed to upload %s: %w", image, err)
+		}
+		keys[image] = key
+	}
+
+	if manifest != "" {
+		key := filepath.Join(requestHash, "manifest.json")
+		if _, err := store.Upload(ctx, key, strings.NewReader(manifest), int64(len(manifest))); err != nil {
+			return "", fmt.Errorf("failed to upload manifest.json: %w", err)
+		}
+		keys["manifest.json"] = key
+	}
+
+	data, err := json.Marshal(keys)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal artifact keys: %w", err)
+	}
+
+	return string(data), nil
+}
@@ -0,0 +1,62 @@
+package queue
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/aparcar/asu/builder/internal/events"
+)
+
+// BusPoster publishes builder-wide lifecycle events (see the events
+// package) by POSTing each one to the server's internal events endpoint,
+// the same loose coupling EventPoster uses for container events so this
+// keeps working unchanged once workers run as a separate process from the
+// API server.
+type BusPoster struct {
+	baseURL     string
+	workerToken string
+	client      *http.Client
+}
+
+// NewBusPoster creates a BusPoster that posts to baseURL's internal events
+// endpoint.
+func NewBusPoster(baseURL, workerToken string) *BusPoster {
+	return &BusPoster{
+		baseURL:     baseURL,
+		workerToken: workerToken,
+		client:      &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// Publish best-effort POSTs the event to the server; a delivery failure here must
+// never fail the build, so it's logged by the caller at most.
+func (p *BusPoster) Publish(typ events.Type, requestHash string, data any) error {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		payload = nil
+	}
+
+	body, err := json.Marshal(events.Event{Type: typ, RequestHash: requestHash, Data: payload})
+	if err != nil {
+		return fmt.Errorf("failed to marshal event: %w", err)
+	}
+
+	req, err := http.NewRequest(http.MethodPost, p.baseURL+"/api/v1/internal/events", bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create event request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if p.workerToken != "" {
+		req.Header.Set("X-Worker-Token", p.workerToken)
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to reach event sink: %w", err)
+	}
+	defer resp.Body.Close()
+	return nil
+}
@@ -0,0 +1,74 @@
+package queue
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"sync/atomic"
+	"time"
+)
+
+// watchCancellation polls the server for a cancellation request on
+// requestHash and, as soon as one arrives, flips cancelled and cancels
+// buildCancel so the build can be torn down. It returns once a
+// cancellation is observed or ctx is done (the build finished on its own).
+func (w *Worker) watchCancellation(ctx context.Context, requestHash string, buildCancel context.CancelFunc, cancelled *atomic.Bool) {
+	watchCancellation(ctx, w.serverBaseURL(), w.config.WorkerToken, time.Duration(w.config.CancelPollIntervalSeconds)*time.Second, requestHash, buildCancel, cancelled)
+}
+
+// watchCancellation is the baseURL-parameterized implementation shared by
+// Worker and RemoteWorker, since a runner polls the very same endpoint for
+// cancellation regardless of whether it shares a process with the server.
+func watchCancellation(ctx context.Context, baseURL, workerToken string, interval time.Duration, requestHash string, buildCancel context.CancelFunc, cancelled *atomic.Bool) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	client := &http.Client{Timeout: interval}
+	url := fmt.Sprintf("%s/api/v1/internal/build/%s/cancel-requested", baseURL, requestHash)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			requested, err := pollCancelRequested(client, url, workerToken)
+			if err != nil {
+				log.Printf("Failed to poll cancellation status for %s: %v", requestHash, err)
+				continue
+			}
+			if requested {
+				log.Printf("Cancellation requested for %s, stopping build", requestHash)
+				cancelled.Store(true)
+				buildCancel()
+				return
+			}
+		}
+	}
+}
+
+func pollCancelRequested(client *http.Client, url, workerToken string) (bool, error) {
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return false, err
+	}
+	if workerToken != "" {
+		req.Header.Set("X-Worker-Token", workerToken)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return false, err
+	}
+	defer resp.Body.Close()
+
+	var body struct {
+		CancelRequested bool `json:"cancel_requested"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return false, fmt.Errorf("failed to decode cancellation response: %w", err)
+	}
+
+	return body.CancelRequested, nil
+}
@@ -0,0 +1,92 @@
+package queue
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/aparcar/asu/builder/internal/container"
+	"github.com/aparcar/asu/builder/internal/models"
+)
+
+// EventPoster implements builder.EventRecorder by POSTing each container
+// lifecycle event to the server's internal build-events endpoint. It lets a
+// worker publish container events without needing direct access to the
+// server's in-memory event registry, which is what makes it work unchanged
+// once workers run as a separate process from the API server.
+type EventPoster struct {
+	baseURL     string
+	requestHash string
+	workerToken string
+	client      *http.Client
+}
+
+// NewEventPoster creates an EventPoster that posts requestHash's container
+// events to baseURL's internal build-events endpoint.
+func NewEventPoster(baseURL, requestHash, workerToken string) *EventPoster {
+	return &EventPoster{
+		baseURL:     baseURL,
+		requestHash: requestHash,
+		workerToken: workerToken,
+		client:      &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// Record implements builder.EventRecorder, best-effort POSTing ev to the
+// server. Failures are swallowed: an unreachable event sink must never fail
+// the build.
+func (p *EventPoster) Record(ev container.Event) {
+	event := models.BuildContainerEvent{
+		Timestamp: ev.Time,
+		Type:      ev.Type,
+		Status:    ev.Status,
+	}
+
+	body, err := json.Marshal(event)
+	if err != nil {
+		return
+	}
+
+	req, err := http.NewRequest(http.MethodPost, p.eventsURL(), bytes.NewReader(body))
+	if err != nil {
+		return
+	}
+	req.Header.Set("Content-Type", "application/json")
+	p.setAuth(req)
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return
+	}
+	resp.Body.Close()
+}
+
+// Close tells the server this build's event stream is done, so live
+// subscribers get a clean end-of-stream event instead of hanging until they
+// time out.
+func (p *EventPoster) Close() error {
+	req, err := http.NewRequest(http.MethodPost, p.eventsURL()+"/close", nil)
+	if err != nil {
+		return nil
+	}
+	p.setAuth(req)
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil
+	}
+	resp.Body.Close()
+	return nil
+}
+
+func (p *EventPoster) setAuth(req *http.Request) {
+	if p.workerToken != "" {
+		req.Header.Set("X-Worker-Token", p.workerToken)
+	}
+}
+
+func (p *EventPoster) eventsURL() string {
+	return fmt.Sprintf("%s/api/v1/internal/build/%s/events", p.baseURL, p.requestHash)
+}
@@ -0,0 +1,130 @@
+package queue
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/aparcar/asu/builder/internal/models"
+)
+
+// LineWriter is an io.Writer that splits incoming writes on newlines and
+// POSTs each completed line to the server's internal build-logs endpoint.
+// It lets a worker stream build output without needing direct access to the
+// server's in-memory log registry, which is what makes it work unchanged
+// once workers run as a separate process from the API server.
+type LineWriter struct {
+	baseURL     string
+	requestHash string
+	stream      string
+	step        string
+	workerToken string
+	client      *http.Client
+	buf         bytes.Buffer
+}
+
+// NewLineWriter creates a LineWriter that posts stream-tagged log lines for
+// requestHash to baseURL's internal build-logs endpoint.
+func NewLineWriter(baseURL, requestHash, stream, workerToken string) *LineWriter {
+	return &LineWriter{
+		baseURL:     baseURL,
+		requestHash: requestHash,
+		stream:      stream,
+		workerToken: workerToken,
+		client:      &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// SetStep tags subsequent log lines with the build phase that produced
+// them, so clients can filter the stream by step.
+func (w *LineWriter) SetStep(step string) {
+	w.step = step
+}
+
+// Write implements io.Writer, buffering partial lines and POSTing complete
+// ones to the server as they arrive.
+func (w *LineWriter) Write(p []byte) (int, error) {
+	w.buf.Write(p)
+
+	for {
+		data := w.buf.Bytes()
+		idx := bytes.IndexByte(data, '\n')
+		if idx < 0 {
+			break
+		}
+
+		line := string(data[:idx])
+		w.buf.Next(idx + 1)
+		w.postLine(line)
+	}
+
+	return len(p), nil
+}
+
+// Close flushes any trailing partial line that was never newline-terminated
+// and tells the server this stream is done, so live subscribers get a clean
+// end-of-stream event.
+func (w *LineWriter) Close() error {
+	if w.buf.Len() > 0 {
+		w.postLine(w.buf.String())
+		w.buf.Reset()
+	}
+
+	req, err := http.NewRequest(http.MethodPost, w.closeURL(), nil)
+	if err != nil {
+		return nil
+	}
+	w.setAuth(req)
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return nil
+	}
+	resp.Body.Close()
+	return nil
+}
+
+// postLine best-effort POSTs a single log line. Failures are swallowed: a
+// slow or unreachable log sink must never fail the build.
+func (w *LineWriter) postLine(text string) {
+	line := models.BuildLogLine{
+		Timestamp: time.Now(),
+		Stream:    w.stream,
+		Text:      text,
+		Step:      w.step,
+	}
+
+	body, err := json.Marshal(line)
+	if err != nil {
+		return
+	}
+
+	req, err := http.NewRequest(http.MethodPost, w.logsURL(), bytes.NewReader(body))
+	if err != nil {
+		return
+	}
+	req.Header.Set("Content-Type", "application/json")
+	w.setAuth(req)
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return
+	}
+	resp.Body.Close()
+}
+
+func (w *LineWriter) setAuth(req *http.Request) {
+	if w.workerToken != "" {
+		req.Header.Set("X-Worker-Token", w.workerToken)
+	}
+}
+
+func (w *LineWriter) logsURL() string {
+	return fmt.Sprintf("%s/api/v1/internal/build/%s/logs", w.baseURL, w.requestHash)
+}
+
+func (w *LineWriter) closeURL() string {
+	return w.logsURL() + "/close"
+}
@@ -4,30 +4,71 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"log"
+	"log/slog"
+	"sync/atomic"
 	"time"
 
+	"github.com/aparcar/asu/builder/internal/artifacts"
 	"github.com/aparcar/asu/builder/internal/builder"
 	"github.com/aparcar/asu/builder/internal/config"
+	"github.com/aparcar/asu/builder/internal/container"
 	"github.com/aparcar/asu/builder/internal/db"
+	"github.com/aparcar/asu/builder/internal/events"
+	"github.com/aparcar/asu/builder/internal/failures"
+	"github.com/aparcar/asu/builder/internal/jobqueue"
 	"github.com/aparcar/asu/builder/internal/models"
+	"github.com/aparcar/asu/builder/internal/signer"
+	"github.com/aparcar/asu/builder/internal/telemetry"
+	"go.opentelemetry.io/otel"
 )
 
 // Worker processes build jobs from the queue
 type Worker struct {
-	db      *db.DB
-	builder *builder.BuilderWithPodman
-	config  *config.Config
-	stopCh  chan struct{}
+	db            *db.DB
+	builder       *builder.Builder
+	config        *config.Config
+	signer        signer.Signer   // nil if signing is disabled (config.SigningBackend == "")
+	artifactStore artifacts.Store // nil if no artifact store is configured (config.ArtifactStoreBackend == "")
+	metrics       *telemetry.Metrics
+	stopCh        chan struct{}
 }
 
 // NewWorker creates a new worker instance
-func NewWorker(database *db.DB, bldr *builder.BuilderWithPodman, cfg *config.Config) *Worker {
+func NewWorker(database *db.DB, bldr *builder.Builder, cfg *config.Config, metrics *telemetry.Metrics) *Worker {
+	s, err := signer.New(signer.Config{
+		Backend:                   cfg.SigningBackend,
+		GPGKeyPath:                cfg.SigningGPGKeyPath,
+		GPGPassphrase:             cfg.SigningGPGKeyPassphrase,
+		SigstoreFulcioURL:         cfg.SigstoreFulcioURL,
+		SigstoreRekorURL:          cfg.SigstoreRekorURL,
+		SigstoreIdentityTokenPath: cfg.SigstoreIdentityTokenPath,
+	})
+	if err != nil {
+		slog.Warn("Artifact signing disabled", "error", err)
+	}
+
+	store, err := artifacts.New(artifacts.Config{
+		Backend:       cfg.ArtifactStoreBackend,
+		Endpoint:      cfg.ArtifactStoreEndpoint,
+		Bucket:        cfg.ArtifactStoreBucket,
+		Region:        cfg.ArtifactStoreRegion,
+		AccessKey:     cfg.ArtifactStoreAccessKey,
+		SecretKey:     cfg.ArtifactStoreSecretKey,
+		UseSSL:        cfg.ArtifactStoreUseSSL,
+		PresignExpiry: time.Duration(cfg.ArtifactPresignExpirySecs) * time.Second,
+	})
+	if err != nil {
+		slog.Warn("Artifact store disabled", "error", err)
+	}
+
 	return &Worker{
-		db:      database,
-		builder: bldr,
-		config:  cfg,
-		stopCh:  make(chan struct{}),
+		db:            database,
+		builder:       bldr,
+		config:        cfg,
+		signer:        s,
+		artifactStore: store,
+		metrics:       metrics,
+		stopCh:        make(chan struct{}),
 	}
 }
 
@@ -36,7 +77,7 @@ func (w *Worker) Start(ctx context.Context) {
 	ticker := time.NewTicker(time.Duration(w.config.WorkerPollSecs) * time.Second)
 	defer ticker.Stop()
 
-	log.Printf("Worker %s started, polling every %d seconds", w.config.WorkerID, w.config.WorkerPollSecs)
+	slog.Info("Worker started", "event_type", "worker_started", "worker_id", w.config.WorkerID, "poll_interval_seconds", w.config.WorkerPollSecs)
 
 	// Process immediately on start
 	w.processJobs(ctx)
@@ -44,10 +85,10 @@ func (w *Worker) Start(ctx context.Context) {
 	for {
 		select {
 		case <-ctx.Done():
-			log.Println("Worker shutting down...")
+			slog.Info("Worker shutting down...", "worker_id", w.config.WorkerID)
 			return
 		case <-w.stopCh:
-			log.Println("Worker stopped")
+			slog.Info("Worker stopped", "worker_id", w.config.WorkerID)
 			return
 		case <-ticker.C:
 			w.processJobs(ctx)
@@ -64,7 +105,7 @@ func (w *Worker) Stop() {
 func (w *Worker) processJobs(ctx context.Context) {
 	jobs, err := w.db.GetPendingJobs()
 	if err != nil {
-		log.Printf("Failed to get pending jobs: %v", err)
+		slog.Error("Failed to get pending jobs", "error", err)
 		return
 	}
 
@@ -72,7 +113,7 @@ func (w *Worker) processJobs(ctx context.Context) {
 		return
 	}
 
-	log.Printf("Found %d pending job(s)", len(jobs))
+	slog.Info("Found pending job(s)", "count", len(jobs))
 
 	// Process jobs up to worker concurrency limit
 	limit := w.config.WorkerConcurrent
@@ -88,25 +129,41 @@ func (w *Worker) processJobs(ctx context.Context) {
 
 // processJob processes a single build job
 func (w *Worker) processJob(ctx context.Context, job *models.BuildJob) {
-	log.Printf("Processing job %s (request_hash: %s)", job.ID, job.RequestHash)
+	ctx, span := otel.Tracer("internal/queue").Start(ctx, "queue.process_job")
+	defer span.End()
+
+	slog.Info("Processing job", "event_type", "job_processing", "job_id", job.ID, "request_hash", job.RequestHash)
 
-	// Mark job as building
-	if err := w.db.StartBuildJob(job.RequestHash, w.config.WorkerID); err != nil {
-		log.Printf("Failed to start job %s: %v", job.RequestHash, err)
+	leaseDuration := time.Duration(w.config.LeaseDurationSeconds) * time.Second
+
+	// Mark job as building and acquire a lease on it
+	if err := w.db.StartBuildJob(job.RequestHash, w.config.WorkerID, leaseDuration); err != nil {
+		slog.Error("Failed to start job", "request_hash", job.RequestHash, "error", err)
 		return
 	}
 
+	// Renew the lease periodically for the lifetime of the build so a long
+	// build isn't reclaimed out from under us by the sweeper.
+	renewCtx, stopRenewing := context.WithCancel(ctx)
+	defer stopRenewing()
+	go w.renewLeaseLoop(renewCtx, job.RequestHash, leaseDuration)
+
+	// Stream build output to the server so clients can watch progress
+	// instead of polling for status.
+	logWriter := NewLineWriter(w.serverBaseURL(), job.RequestHash, "stdout", w.config.WorkerToken)
+	defer logWriter.Close()
+
 	// Get build request
 	buildReq, err := w.db.GetBuildRequest(job.RequestHash)
 	if err != nil {
-		log.Printf("Failed to get build request %s: %v", job.RequestHash, err)
-		w.db.FailBuildJob(job.RequestHash, fmt.Sprintf("Failed to get build request: %v", err))
+		slog.Error("Failed to get build request", "request_hash", job.RequestHash, "error", err)
+		w.db.FailBuildJob(job.RequestHash, w.config.WorkerID, fmt.Sprintf("Failed to get build request: %v", err), models.FailurePermanent, w.retryPolicy())
 		return
 	}
 
 	if buildReq == nil {
-		log.Printf("Build request %s not found", job.RequestHash)
-		w.db.FailBuildJob(job.RequestHash, "Build request not found")
+		slog.Error("Build request not found", "request_hash", job.RequestHash)
+		w.db.FailBuildJob(job.RequestHash, w.config.WorkerID, "Build request not found", models.FailurePermanent, w.retryPolicy())
 		return
 	}
 
@@ -114,19 +171,91 @@ func (w *Worker) processJob(ctx context.Context, job *models.BuildJob) {
 	buildCtx, cancel := context.WithTimeout(ctx, time.Duration(w.config.JobTimeoutSeconds)*time.Second)
 	defer cancel()
 
+	// Watch for a user-requested cancellation and tear the build down if
+	// one arrives.
+	var cancelled atomic.Bool
+	watchCtx, stopWatching := context.WithCancel(ctx)
+	defer stopWatching()
+	go w.watchCancellation(watchCtx, job.RequestHash, cancel, &cancelled)
+
+	steps := &dbStepRecorder{
+		db:          w.db,
+		logWriter:   logWriter,
+		requestHash: job.RequestHash,
+		workerID:    w.config.WorkerID,
+	}
+
+	containerEvents := NewEventPoster(w.serverBaseURL(), job.RequestHash, w.config.WorkerToken)
+	defer containerEvents.Close()
+
+	pulls := &dbPullRecorder{db: w.db}
+
+	busPoster := NewBusPoster(w.serverBaseURL(), w.config.WorkerToken)
+	progress := &busProgressRecorder{poster: busPoster, requestHash: job.RequestHash}
+	cache := &dbCacheRecorder{db: w.db}
+
+	if err := busPoster.Publish(events.TypeJobStarted, job.RequestHash, nil); err != nil {
+		slog.Error("Failed to publish job.started event", "request_hash", job.RequestHash, "error", err)
+	}
+
 	// Execute build
+	fmt.Fprintf(logWriter, "Starting build for %s (%s/%s)\n", job.RequestHash, buildReq.Target, buildReq.Profile)
+	slog.Info("Build started",
+		"event_type", "job_started",
+		"request_hash", job.RequestHash,
+		"distro", buildReq.Distro,
+		"version", buildReq.Version,
+		"target", buildReq.Target,
+		"profile", buildReq.Profile,
+	)
 	startTime := time.Now()
-	result := w.builder.Build(buildCtx, buildReq)
+	result := w.builder.Build(buildCtx, buildReq, steps, containerEvents, pulls, progress, cache, logWriter)
 	duration := time.Since(startTime)
 
+	// A cancellation always wins, whether or not the runtime actually
+	// aborted the underlying build in time to surface as result.Error.
+	if cancelled.Load() {
+		fmt.Fprintf(logWriter, "Build cancelled\n")
+		slog.Info("Build cancelled",
+			"event_type", "job_cancelled",
+			"request_hash", job.RequestHash,
+			"distro", buildReq.Distro,
+			"version", buildReq.Version,
+			"target", buildReq.Target,
+			"profile", buildReq.Profile,
+			"duration_seconds", duration.Seconds(),
+		)
+		if err := w.db.CancelRunningJob(job.RequestHash, w.config.WorkerID); err != nil {
+			slog.Error("Failed to mark job as cancelled", "request_hash", job.RequestHash, "error", err)
+		}
+
+		// Record cancellation stat
+		w.db.RecordEvent(models.EventTypeCancelled, buildReq.Version, buildReq.Target, buildReq.Profile, 0, buildReq.DiffPackages, nil)
+		return
+	}
+
 	if result.Error != nil {
-		log.Printf("Build failed for %s: %v", job.RequestHash, result.Error)
-		if err := w.db.FailBuildJob(job.RequestHash, result.Error.Error()); err != nil {
-			log.Printf("Failed to mark job as failed: %v", err)
+		fmt.Fprintf(logWriter, "Build failed: %v\n", result.Error)
+		slog.Error("Build failed",
+			"event_type", "job_failed",
+			"request_hash", job.RequestHash,
+			"distro", buildReq.Distro,
+			"version", buildReq.Version,
+			"target", buildReq.Target,
+			"profile", buildReq.Profile,
+			"duration_seconds", duration.Seconds(),
+			"error", result.Error,
+		)
+		class := failures.Classify(result.Error.Error())
+		if err := w.db.FailBuildJob(job.RequestHash, w.config.WorkerID, result.Error.Error(), class, w.retryPolicy()); err != nil {
+			slog.Error("Failed to mark job as failed", "request_hash", job.RequestHash, "error", err)
 		}
 
 		// Record failure stat
-		w.db.RecordEvent(models.EventTypeFailure, buildReq.Version, buildReq.Target, buildReq.Profile, 0)
+		w.db.RecordEvent(models.EventTypeFailure, buildReq.Version, buildReq.Target, buildReq.Profile, 0, buildReq.DiffPackages, nil)
+		if err := busPoster.Publish(events.TypeJobFailed, job.RequestHash, map[string]string{"error": result.Error.Error()}); err != nil {
+			slog.Error("Failed to publish job.failed event", "request_hash", job.RequestHash, "error", err)
+		}
 		return
 	}
 
@@ -142,7 +271,7 @@ func (w *Worker) processJob(ctx context.Context, job *models.BuildJob) {
 	if len(result.Images) > 0 {
 		imagesJSON, err := json.Marshal(result.Images)
 		if err != nil {
-			log.Printf("Failed to marshal images: %v", err)
+			slog.Error("Failed to marshal images", "request_hash", job.RequestHash, "error", err)
 		} else {
 			buildResult.Images = string(imagesJSON)
 		}
@@ -150,24 +279,244 @@ func (w *Worker) processJob(ctx context.Context, job *models.BuildJob) {
 
 	buildResult.Manifest = result.Manifest
 
+	if w.signer != nil {
+		steps.StartStep(models.StepSign)
+		signatures, fingerprint, err := signArtifacts(w.signer, buildDir(w.config, job.RequestHash), result.Manifest, result.Images)
+		if err != nil {
+			fmt.Fprintf(logWriter, "Failed to sign build artifacts: %v\n", err)
+			slog.Error("Failed to sign artifacts", "request_hash", job.RequestHash, "error", err)
+			steps.FailStep(models.StepSign, err)
+		} else {
+			buildResult.Signatures = signatures
+			buildResult.SigningKeyFingerprint = fingerprint
+			steps.CompleteStep(models.StepSign)
+		}
+	}
+
+	if w.artifactStore != nil {
+		steps.StartStep(models.StepPackage)
+		uploadCtx, uploadSpan := otel.Tracer("internal/queue").Start(ctx, "artifact_upload")
+		urls, err := uploadArtifacts(uploadCtx, w.artifactStore, buildDir(w.config, job.RequestHash), job.RequestHash, result.Manifest, result.Images)
+		uploadSpan.End()
+		if err != nil {
+			fmt.Fprintf(logWriter, "Failed to upload build artifacts: %v\n", err)
+			slog.Error("Failed to upload artifacts", "request_hash", job.RequestHash, "error", err)
+			steps.FailStep(models.StepPackage, err)
+		} else {
+			buildResult.ArtifactURLs = urls
+			steps.CompleteStep(models.StepPackage)
+		}
+	}
+
 	if err := w.db.CreateBuildResult(buildResult); err != nil {
-		log.Printf("Failed to save build result: %v", err)
+		slog.Error("Failed to save build result", "request_hash", job.RequestHash, "error", err)
 	}
 
 	// Mark job as completed
-	if err := w.db.CompleteBuildJob(job.RequestHash, result.BuildCommand, result.Manifest); err != nil {
-		log.Printf("Failed to mark job as completed: %v", err)
+	if err := w.db.CompleteBuildJob(job.RequestHash, w.config.WorkerID, result.BuildCommand, result.Manifest); err != nil {
+		slog.Error("Failed to mark job as completed", "request_hash", job.RequestHash, "error", err)
 		return
 	}
 
-	// Record success stat
-	w.db.RecordEvent(models.EventTypeBuildCompleted, buildReq.Version, buildReq.Target, buildReq.Profile, int(duration.Seconds()))
+	// Record success stat, along with the build container's resource profile
+	resources := &models.ResourceProfile{
+		SampleCount:     result.Resources.SampleCount,
+		PeakCPUPercent:  result.Resources.PeakCPUPercent,
+		AvgCPUPercent:   result.Resources.AvgCPUPercent,
+		PeakMemBytes:    result.Resources.PeakMemBytes,
+		AvgMemBytes:     result.Resources.AvgMemBytes,
+		BlockReadBytes:  result.Resources.BlockReadBytes,
+		BlockWriteBytes: result.Resources.BlockWriteBytes,
+		NetRxBytes:      result.Resources.NetRxBytes,
+		NetTxBytes:      result.Resources.NetTxBytes,
+	}
+	w.db.RecordEvent(models.EventTypeBuildCompleted, buildReq.Version, buildReq.Target, buildReq.Profile, int(duration.Seconds()), buildReq.DiffPackages, resources)
+	w.metrics.RecordBuildDuration(buildReq.Target, buildReq.Profile, buildReq.Version, duration.Seconds())
+
+	fmt.Fprintf(logWriter, "Build completed in %v, images: %v\n", duration, result.Images)
+	slog.Info("Build completed",
+		"event_type", "job_completed",
+		"request_hash", job.RequestHash,
+		"distro", buildReq.Distro,
+		"version", buildReq.Version,
+		"target", buildReq.Target,
+		"profile", buildReq.Profile,
+		"duration_seconds", duration.Seconds(),
+		"images", result.Images,
+	)
+
+	if err := busPoster.Publish(events.TypeJobCompleted, job.RequestHash, map[string]any{"images": result.Images, "duration_seconds": int(duration.Seconds())}); err != nil {
+		slog.Error("Failed to publish job.completed event", "request_hash", job.RequestHash, "error", err)
+	}
+}
+
+// ProcessJobByHash runs processJob for requestHash synchronously and
+// reports whether asynq should retry it, for use as the jobqueue.Handler
+// passed to jobqueue.Queue.RunServer instead of the ticker-driven
+// processJobs loop above. processJob already applies this Worker's own
+// db.RetryPolicy via FailBuildJob, which leaves a transiently-failed job
+// JobStatusPending for either scheduler to pick back up; ProcessJobByHash
+// turns that back into an error so asynq's retry/backoff governs the next
+// attempt instead of waiting on Worker's poll ticker.
+func (w *Worker) ProcessJobByHash(ctx context.Context, requestHash string) error {
+	job, err := w.db.GetBuildJob(requestHash)
+	if err != nil {
+		return fmt.Errorf("failed to get build job %s: %w", requestHash, err)
+	}
+	if job == nil {
+		return fmt.Errorf("build job %s not found", requestHash)
+	}
+
+	w.processJob(ctx, job)
+
+	job, err = w.db.GetBuildJob(requestHash)
+	if err != nil {
+		return fmt.Errorf("failed to reload build job %s: %w", requestHash, err)
+	}
+	if job != nil && job.Status == models.JobStatusPending {
+		return fmt.Errorf("build job %s failed transiently, retrying", requestHash)
+	}
+
+	return nil
+}
 
-	log.Printf("Build completed for %s in %v, images: %v", job.RequestHash, duration, result.Images)
+// busProgressRecorder implements builder.ProgressRecorder by publishing
+// each ImageBuilder pull progress update as an image.pull.progress event
+// (see BusPoster), so live GET /api/v1/events subscribers see pull
+// progress without needing their own connection to the container runtime.
+type busProgressRecorder struct {
+	poster      *BusPoster
+	requestHash string
 }
 
-// EnqueueJob adds a new build job to the queue
-func EnqueueJob(database *db.DB, req *models.BuildRequest) error {
+func (r *busProgressRecorder) RecordProgress(image string, pr container.PullProgress) {
+	if err := r.poster.Publish(events.TypeImagePullProgress, r.requestHash, map[string]any{
+		"image":  image,
+		"id":     pr.ID,
+		"status": pr.Status,
+	}); err != nil {
+		slog.Error("Failed to publish image.pull.progress event", "request_hash", r.requestHash, "error", err)
+	}
+}
+
+// dbStepRecorder persists per-phase build step transitions to the database
+// and tags the build's log stream with the phase that produced each line,
+// implementing builder.StepRecorder.
+type dbStepRecorder struct {
+	db          *db.DB
+	logWriter   *LineWriter
+	requestHash string
+	workerID    string
+}
+
+func (r *dbStepRecorder) StartStep(name models.StepName) {
+	r.logWriter.SetStep(string(name))
+	fmt.Fprintf(r.logWriter, "Starting %s\n", name)
+	if err := r.db.StartStep(r.requestHash, name, r.workerID); err != nil {
+		slog.Error("Failed to record start of step", "step", name, "request_hash", r.requestHash, "error", err)
+	}
+}
+
+func (r *dbStepRecorder) CompleteStep(name models.StepName) {
+	fmt.Fprintf(r.logWriter, "Completed %s\n", name)
+	if err := r.db.CompleteStep(r.requestHash, name); err != nil {
+		slog.Error("Failed to record completion of step", "step", name, "request_hash", r.requestHash, "error", err)
+	}
+}
+
+func (r *dbStepRecorder) FailStep(name models.StepName, stepErr error) {
+	fmt.Fprintf(r.logWriter, "Step %s failed: %v\n", name, stepErr)
+	// Neither container runtime surfaces a distinct process exit code to
+	// builder.Builder, so -1 marks "unknown" rather than a false 0/1.
+	if err := r.db.FailStep(r.requestHash, name, stepErr.Error(), -1); err != nil {
+		slog.Error("Failed to record failure of step", "step", name, "request_hash", r.requestHash, "error", err)
+	}
+}
+
+// dbPullRecorder persists successful ImageBuilder image pulls to the
+// database, implementing builder.PullRecorder.
+type dbPullRecorder struct {
+	db *db.DB
+}
+
+func (r *dbPullRecorder) RecordPull(image string, pulledAt time.Time) {
+	if err := r.db.RecordImagePull(image, pulledAt); err != nil {
+		slog.Error("Failed to record image pull", "image", image, "error", err)
+	}
+}
+
+// dbCacheRecorder persists each build's shared download-cache hit/miss
+// outcome and refreshed entry metadata to the database, implementing
+// builder.CacheRecorder.
+type dbCacheRecorder struct {
+	db *db.DB
+}
+
+func (r *dbCacheRecorder) RecordCacheUsage(version, target string, hit bool, entries map[string]int64) {
+	var err error
+	if hit {
+		err = r.db.RecordCacheHit(version, target)
+	} else {
+		err = r.db.RecordCacheMiss(version, target)
+	}
+	if err != nil {
+		slog.Error("Failed to record build cache usage", "version", version, "target", target, "error", err)
+	}
+
+	now := time.Now()
+	for name, size := range entries {
+		if err := r.db.UpsertDownloadCacheEntry(name, size, now); err != nil {
+			slog.Error("Failed to upsert download cache entry", "cache_key", name, "error", err)
+		}
+	}
+}
+
+// retryPolicy builds the db.RetryPolicy governing how transiently failed
+// jobs are requeued, from the worker's configured retry settings.
+func (w *Worker) retryPolicy() db.RetryPolicy {
+	return db.RetryPolicy{
+		MaxAttempts:    w.config.RetryMaxAttempts,
+		InitialBackoff: time.Duration(w.config.RetryInitialBackoffSeconds) * time.Second,
+		MaxBackoff:     time.Duration(w.config.RetryMaxBackoffSeconds) * time.Second,
+		Multiplier:     w.config.RetryBackoffMultiplier,
+	}
+}
+
+// serverBaseURL returns the URL the worker uses to reach its own API
+// server's internal endpoints. Workers and the API server share a process
+// today, so this is always localhost, but routing it through config now
+// means a future out-of-process worker needs no code change here.
+func (w *Worker) serverBaseURL() string {
+	return fmt.Sprintf("http://localhost:%d", w.config.ServerPort)
+}
+
+// renewLeaseLoop renews requestHash's lease at half the lease duration until
+// ctx is cancelled (the build finished or the worker is shutting down).
+func (w *Worker) renewLeaseLoop(ctx context.Context, requestHash string, leaseDuration time.Duration) {
+	ticker := time.NewTicker(leaseDuration / 2)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := w.db.RenewLease(requestHash, w.config.WorkerID, leaseDuration); err != nil {
+				slog.Error("Failed to renew lease", "request_hash", requestHash, "error", err)
+			}
+		}
+	}
+}
+
+// EnqueueJob adds a new build job to the queue. If jq is non-nil (see the
+// jobqueue package), the build is additionally submitted to asynq so a
+// jobqueue.Queue.RunServer handler picks it up instead of leaving it to
+// Worker's own polling loop alone; SQLite remains the source of truth for
+// job status and results either way.
+func EnqueueJob(ctx context.Context, database *db.DB, jq *jobqueue.Queue, req *models.BuildRequest) error {
+	_, span := otel.Tracer("internal/queue").Start(ctx, "queue.enqueue_job")
+	defer span.End()
+
 	// Check if already in queue or completed
 	existingJob, err := database.GetBuildJob(req.RequestHash)
 	if err != nil {
@@ -209,8 +558,19 @@ func EnqueueJob(database *db.DB, req *models.BuildRequest) error {
 		return fmt.Errorf("failed to create build job: %w", err)
 	}
 
-	log.Printf("Enqueued job for request %s at position %d", req.RequestHash, job.QueuePosition)
+	if err := jq.Enqueue(req.RequestHash, req.Target); err != nil {
+		slog.Error("Failed to submit build to asynq", "request_hash", req.RequestHash, "error", err)
+	}
+
+	slog.Info("Enqueued job",
+		"event_type", "job_enqueued",
+		"request_hash", req.RequestHash,
+		"distro", req.Distro,
+		"version", req.Version,
+		"target", req.Target,
+		"profile", req.Profile,
+		"queue_position", job.QueuePosition,
+	)
 
 	return nil
 }
-
@@ -0,0 +1,279 @@
+package queue
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync/atomic"
+	"time"
+
+	"github.com/aparcar/asu/builder/internal/artifacts"
+	"github.com/aparcar/asu/builder/internal/builder"
+	"github.com/aparcar/asu/builder/internal/config"
+	"github.com/aparcar/asu/builder/internal/events"
+	"github.com/aparcar/asu/builder/internal/failures"
+	"github.com/aparcar/asu/builder/internal/models"
+	"github.com/aparcar/asu/builder/internal/rpc"
+	"github.com/aparcar/asu/builder/internal/signer"
+)
+
+// RemoteWorker processes build jobs leased from a remote serve process over
+// the rpc package's HTTP API, instead of reading the job queue straight out
+// of the database the way Worker does. It is what the "runner" subcommand
+// runs, so build execution (and the container runtime privileges it needs)
+// can live in a process separate from the one serving the API and owning
+// the database.
+//
+// Step, pull, and build-cache telemetry (see dbStepRecorder, dbPullRecorder,
+// dbCacheRecorder) are database-only today and so are not recorded by a
+// remote runner; log lines, container events, and bus events still stream
+// to the server exactly as they do for Worker, since LineWriter,
+// EventPoster, and BusPoster already talk HTTP rather than the database.
+type RemoteWorker struct {
+	client        *rpc.Client
+	builder       *builder.Builder
+	config        *config.Config
+	signer        signer.Signer   // nil if signing is disabled (config.SigningBackend == "")
+	artifactStore artifacts.Store // nil if no artifact store is configured (config.ArtifactStoreBackend == "")
+	stopCh        chan struct{}
+}
+
+// NewRemoteWorker creates a RemoteWorker that leases jobs from client and
+// executes them with bldr.
+func NewRemoteWorker(client *rpc.Client, bldr *builder.Builder, cfg *config.Config) *RemoteWorker {
+	s, err := signer.New(signer.Config{
+		Backend:                   cfg.SigningBackend,
+		GPGKeyPath:                cfg.SigningGPGKeyPath,
+		GPGPassphrase:             cfg.SigningGPGKeyPassphrase,
+		SigstoreFulcioURL:         cfg.SigstoreFulcioURL,
+		SigstoreRekorURL:          cfg.SigstoreRekorURL,
+		SigstoreIdentityTokenPath: cfg.SigstoreIdentityTokenPath,
+	})
+	if err != nil {
+		slog.Warn("Artifact signing disabled", "error", err)
+	}
+
+	store, err := artifacts.New(artifacts.Config{
+		Backend:       cfg.ArtifactStoreBackend,
+		Endpoint:      cfg.ArtifactStoreEndpoint,
+		Bucket:        cfg.ArtifactStoreBucket,
+		Region:        cfg.ArtifactStoreRegion,
+		AccessKey:     cfg.ArtifactStoreAccessKey,
+		SecretKey:     cfg.ArtifactStoreSecretKey,
+		UseSSL:        cfg.ArtifactStoreUseSSL,
+		PresignExpiry: time.Duration(cfg.ArtifactPresignExpirySecs) * time.Second,
+	})
+	if err != nil {
+		slog.Warn("Artifact store disabled", "error", err)
+	}
+
+	return &RemoteWorker{
+		client:        client,
+		builder:       bldr,
+		config:        cfg,
+		signer:        s,
+		artifactStore: store,
+		stopCh:        make(chan struct{}),
+	}
+}
+
+// Start begins leasing and processing jobs, and sends a heartbeat every
+// config.RunnerHeartbeatIntervalSeconds so GET /api/v1/runners shows this
+// runner as alive.
+func (w *RemoteWorker) Start(ctx context.Context) {
+	pollTicker := time.NewTicker(time.Duration(w.config.WorkerPollSecs) * time.Second)
+	defer pollTicker.Stop()
+	heartbeatTicker := time.NewTicker(time.Duration(w.config.RunnerHeartbeatIntervalSeconds) * time.Second)
+	defer heartbeatTicker.Stop()
+
+	slog.Info("Runner started", "event_type", "runner_started", "worker_id", w.config.WorkerID, "server_url", w.config.RunnerServerURL)
+
+	w.leaseAndProcess(ctx)
+
+	for {
+		select {
+		case <-ctx.Done():
+			slog.Info("Runner shutting down...", "worker_id", w.config.WorkerID)
+			return
+		case <-w.stopCh:
+			slog.Info("Runner stopped", "worker_id", w.config.WorkerID)
+			return
+		case <-pollTicker.C:
+			w.leaseAndProcess(ctx)
+		case <-heartbeatTicker.C:
+			if err := w.client.Heartbeat(ctx, ""); err != nil {
+				slog.Error("Failed to send runner heartbeat", "error", err)
+			}
+		}
+	}
+}
+
+// Stop signals the runner to stop.
+func (w *RemoteWorker) Stop() {
+	close(w.stopCh)
+}
+
+// leaseAndProcess leases up to config.WorkerConcurrent jobs and processes
+// each in its own goroutine, stopping as soon as a lease attempt comes back
+// empty.
+func (w *RemoteWorker) leaseAndProcess(ctx context.Context) {
+	for i := 0; i < w.config.WorkerConcurrent; i++ {
+		leased, err := w.client.LeaseBuild(ctx)
+		if err != nil {
+			slog.Error("Failed to lease build job", "error", err)
+			return
+		}
+		if leased == nil {
+			return
+		}
+
+		slog.Info("Leased job", "event_type", "job_leased", "job_id", leased.Job.ID, "request_hash", leased.Job.RequestHash)
+		go w.processJob(ctx, leased)
+	}
+}
+
+// processJob executes a single leased build job and reports its outcome
+// back to the server, mirroring Worker.processJob's logic but over the rpc
+// package instead of direct database access.
+func (w *RemoteWorker) processJob(ctx context.Context, leased *rpc.LeasedJob) {
+	job, buildReq := leased.Job, leased.Request
+
+	leaseDuration := time.Duration(w.config.LeaseDurationSeconds) * time.Second
+
+	// Renew the lease periodically for the lifetime of the build so a long
+	// build isn't reclaimed out from under us by the sweeper.
+	renewCtx, stopRenewing := context.WithCancel(ctx)
+	defer stopRenewing()
+	go w.renewLeaseLoop(renewCtx, job.RequestHash, leaseDuration)
+
+	logWriter := NewLineWriter(w.config.RunnerServerURL, job.RequestHash, "stdout", w.config.WorkerToken)
+	defer logWriter.Close()
+
+	buildCtx, cancel := context.WithTimeout(ctx, time.Duration(w.config.JobTimeoutSeconds)*time.Second)
+	defer cancel()
+
+	var cancelled atomic.Bool
+	watchCtx, stopWatching := context.WithCancel(ctx)
+	defer stopWatching()
+	go watchCancellation(watchCtx, w.config.RunnerServerURL, w.config.WorkerToken, time.Duration(w.config.CancelPollIntervalSeconds)*time.Second, job.RequestHash, cancel, &cancelled)
+
+	containerEvents := NewEventPoster(w.config.RunnerServerURL, job.RequestHash, w.config.WorkerToken)
+	defer containerEvents.Close()
+
+	busPoster := NewBusPoster(w.config.RunnerServerURL, w.config.WorkerToken)
+	progress := &busProgressRecorder{poster: busPoster, requestHash: job.RequestHash}
+
+	if err := busPoster.Publish(events.TypeJobStarted, job.RequestHash, nil); err != nil {
+		slog.Error("Failed to publish job.started event", "request_hash", job.RequestHash, "error", err)
+	}
+
+	fmt.Fprintf(logWriter, "Starting build for %s (%s/%s)\n", job.RequestHash, buildReq.Target, buildReq.Profile)
+	slog.Info("Build started",
+		"event_type", "job_started",
+		"request_hash", job.RequestHash,
+		"distro", buildReq.Distro,
+		"version", buildReq.Version,
+		"target", buildReq.Target,
+		"profile", buildReq.Profile,
+	)
+	startTime := time.Now()
+	result := w.builder.Build(buildCtx, buildReq, nil, containerEvents, nil, progress, nil, logWriter)
+	duration := time.Since(startTime)
+
+	if cancelled.Load() {
+		fmt.Fprintf(logWriter, "Build cancelled\n")
+		slog.Info("Build cancelled", "event_type", "job_cancelled", "request_hash", job.RequestHash, "duration_seconds", duration.Seconds())
+		if err := w.client.FailBuild(ctx, job.RequestHash, "cancelled by user", models.FailurePermanent); err != nil {
+			slog.Error("Failed to report build cancellation", "request_hash", job.RequestHash, "error", err)
+		}
+		return
+	}
+
+	if result.Error != nil {
+		fmt.Fprintf(logWriter, "Build failed: %v\n", result.Error)
+		slog.Error("Build failed", "event_type", "job_failed", "request_hash", job.RequestHash, "duration_seconds", duration.Seconds(), "error", result.Error)
+		class := failures.Classify(result.Error.Error())
+		if err := w.client.FailBuild(ctx, job.RequestHash, result.Error.Error(), class); err != nil {
+			slog.Error("Failed to report build failure", "request_hash", job.RequestHash, "error", err)
+		}
+		if err := busPoster.Publish(events.TypeJobFailed, job.RequestHash, map[string]string{"error": result.Error.Error()}); err != nil {
+			slog.Error("Failed to publish job.failed event", "request_hash", job.RequestHash, "error", err)
+		}
+		return
+	}
+
+	completeReq := rpc.CompleteBuildRequest{
+		BuildCmd:        result.BuildCommand,
+		Manifest:        result.Manifest,
+		Images:          result.Images,
+		DurationSeconds: int(duration.Seconds()),
+		Resources: &models.ResourceProfile{
+			SampleCount:     result.Resources.SampleCount,
+			PeakCPUPercent:  result.Resources.PeakCPUPercent,
+			AvgCPUPercent:   result.Resources.AvgCPUPercent,
+			PeakMemBytes:    result.Resources.PeakMemBytes,
+			AvgMemBytes:     result.Resources.AvgMemBytes,
+			BlockReadBytes:  result.Resources.BlockReadBytes,
+			BlockWriteBytes: result.Resources.BlockWriteBytes,
+			NetRxBytes:      result.Resources.NetRxBytes,
+			NetTxBytes:      result.Resources.NetTxBytes,
+		},
+	}
+
+	if w.signer != nil {
+		signatures, fingerprint, err := signArtifacts(w.signer, buildDir(w.config, job.RequestHash), result.Manifest, result.Images)
+		if err != nil {
+			fmt.Fprintf(logWriter, "Failed to sign build artifacts: %v\n", err)
+			slog.Error("Failed to sign artifacts", "request_hash", job.RequestHash, "error", err)
+		} else {
+			completeReq.Signatures = signatures
+			completeReq.SigningKeyFingerprint = fingerprint
+		}
+	}
+
+	if w.artifactStore != nil {
+		urls, err := uploadArtifacts(ctx, w.artifactStore, buildDir(w.config, job.RequestHash), job.RequestHash, result.Manifest, result.Images)
+		if err != nil {
+			fmt.Fprintf(logWriter, "Failed to upload build artifacts: %v\n", err)
+			slog.Error("Failed to upload artifacts", "request_hash", job.RequestHash, "error", err)
+		} else {
+			completeReq.ArtifactURLs = urls
+		}
+	}
+
+	if err := w.client.CompleteBuild(ctx, job.RequestHash, completeReq); err != nil {
+		slog.Error("Failed to report build completion", "request_hash", job.RequestHash, "error", err)
+		return
+	}
+
+	fmt.Fprintf(logWriter, "Build completed in %v, images: %v\n", duration, result.Images)
+	slog.Info("Build completed",
+		"event_type", "job_completed",
+		"request_hash", job.RequestHash,
+		"duration_seconds", duration.Seconds(),
+		"images", result.Images,
+	)
+
+	if err := busPoster.Publish(events.TypeJobCompleted, job.RequestHash, map[string]any{"images": result.Images, "duration_seconds": int(duration.Seconds())}); err != nil {
+		slog.Error("Failed to publish job.completed event", "request_hash", job.RequestHash, "error", err)
+	}
+}
+
+// renewLeaseLoop renews requestHash's lease at half the lease duration
+// until ctx is cancelled (the build finished or the runner is shutting
+// down), mirroring Worker.renewLeaseLoop but over rpc.Client.ExtendLease.
+func (w *RemoteWorker) renewLeaseLoop(ctx context.Context, requestHash string, leaseDuration time.Duration) {
+	ticker := time.NewTicker(leaseDuration / 2)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := w.client.ExtendLease(ctx, requestHash); err != nil {
+				slog.Error("Failed to renew lease", "request_hash", requestHash, "error", err)
+			}
+		}
+	}
+}
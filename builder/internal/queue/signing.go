@@ -0,0 +1,69 @@
+package queue
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/aparcar/asu/builder/internal/config"
+	"github.com/aparcar/asu/builder/internal/signer"
+)
+
+// buildDir returns the directory a build's artifacts were written to,
+// matching builder.Builder's own StorePath/RequestHash layout.
+func buildDir(cfg *config.Config, requestHash string) string {
+	return filepath.Join(cfg.StorePath, requestHash)
+}
+
+// signArtifacts signs every file in images plus manifest with s, writing
+// each signature alongside its artifact in dir (e.g.
+// "openwrt-23.05.3-ath79-generic-squashfs-sysupgrade.bin.asc"). It returns
+// a JSON-encoded map of artifact -> {part: signature filename} for
+// models.BuildResult.Signatures, plus the key or certificate identity
+// that produced the signatures.
+func signArtifacts(s signer.Signer, dir, manifest string, images []string) (signaturesJSON string, identity string, err error) {
+	signatures := make(map[string]map[string]string)
+
+	sign := func(artifact string, data []byte) error {
+		parts, keyIdentity, err := s.Sign(data)
+		if err != nil {
+			return fmt.Errorf("failed to sign %s: %w", artifact, err)
+		}
+		identity = keyIdentity
+
+		files := make(map[string]string, len(parts))
+		for ext, content := range parts {
+			sigName := fmt.Sprintf("%s.%s", artifact, ext)
+			if err := os.WriteFile(filepath.Join(dir, sigName), content, 0644); err != nil {
+				return fmt.Errorf("failed to write signature %s: %w", sigName, err)
+			}
+			files[ext] = sigName
+		}
+		signatures[artifact] = files
+		return nil
+	}
+
+	for _, image := range images {
+		data, err := os.ReadFile(filepath.Join(dir, image))
+		if err != nil {
+			return "", "", fmt.Errorf("failed to read %s for signing: %w", image, err)
+		}
+		if err := sign(image, data); err != nil {
+			return "", "", err
+		}
+	}
+
+	if manifest != "" {
+		if err := sign("manifest.json", []byte(manifest)); err != nil {
+			return "", "", err
+		}
+	}
+
+	data, err := json.Marshal(signatures)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to marshal signatures: %w", err)
+	}
+
+	return string(data), identity, nil
+}
@@ -0,0 +1,210 @@
+// Package rpc implements the client side of the protocol a "runner"
+// process (see cmd/runner) uses to lease, execute, and report on build jobs
+// against a remote "serve" process (see cmd/serve), which owns the database
+// and the HTTP API. It follows the same plain-HTTP,
+// X-Worker-Token-authenticated style as LineWriter/EventPoster/BusPoster
+// (see the queue package) rather than introducing a new transport; log and
+// container-event streaming continue to use those existing clients
+// unchanged.
+package rpc
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/aparcar/asu/builder/internal/models"
+)
+
+// Client talks to a serve process's /api/v1/internal endpoints on behalf of
+// a runner identified by workerID.
+type Client struct {
+	baseURL     string
+	workerID    string
+	workerToken string
+	httpClient  *http.Client
+}
+
+// NewClient creates a Client that leases and reports on build jobs owned by
+// workerID against baseURL.
+func NewClient(baseURL, workerID, workerToken string) *Client {
+	return &Client{
+		baseURL:     baseURL,
+		workerID:    workerID,
+		workerToken: workerToken,
+		httpClient:  &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// LeasedJob bundles a leased build job with the request it refers to, so a
+// runner process (with no direct database access) has everything it needs
+// to execute the build.
+type LeasedJob struct {
+	Job     *models.BuildJob     `json:"job"`
+	Request *models.BuildRequest `json:"request"`
+}
+
+// LeaseBuild claims the next pending build job, if any. It returns a nil
+// LeasedJob and a nil error when there is nothing to lease.
+func (c *Client) LeaseBuild(ctx context.Context) (*LeasedJob, error) {
+	body, err := json.Marshal(struct {
+		WorkerID string `json:"worker_id"`
+	}{c.workerID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal lease request: %w", err)
+	}
+
+	resp, err := c.do(ctx, http.MethodPost, c.baseURL+"/api/v1/internal/lease", body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to reach lease endpoint: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNoContent {
+		return nil, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("lease request failed: %s", resp.Status)
+	}
+
+	var leased LeasedJob
+	if err := json.NewDecoder(resp.Body).Decode(&leased); err != nil {
+		return nil, fmt.Errorf("failed to decode leased job: %w", err)
+	}
+	return &leased, nil
+}
+
+// ExtendLease renews the lease on requestHash, keeping it from being
+// reclaimed by the server's lease sweeper while the runner is still working
+// on it.
+func (c *Client) ExtendLease(ctx context.Context, requestHash string) error {
+	body, err := json.Marshal(struct {
+		WorkerID string `json:"worker_id"`
+	}{c.workerID})
+	if err != nil {
+		return fmt.Errorf("failed to marshal lease extension: %w", err)
+	}
+
+	resp, err := c.do(ctx, http.MethodPost, c.baseURL+"/api/v1/internal/build/"+requestHash+"/lease/extend", body)
+	if err != nil {
+		return fmt.Errorf("failed to reach lease extend endpoint: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusNoContent {
+		return fmt.Errorf("lease extend request failed: %s", resp.Status)
+	}
+	return nil
+}
+
+// CompleteBuildRequest reports a successful build, mirroring the fields
+// queue.Worker.processJob would otherwise write straight to the database.
+type CompleteBuildRequest struct {
+	WorkerID              string                  `json:"worker_id"`
+	BuildCmd              string                  `json:"build_cmd"`
+	Manifest              string                  `json:"manifest"`
+	Images                []string                `json:"images"`
+	DurationSeconds       int                     `json:"build_duration_seconds"`
+	Signatures            string                  `json:"signatures,omitempty"`
+	SigningKeyFingerprint string                  `json:"signing_key_fingerprint,omitempty"`
+	ArtifactURLs          string                  `json:"artifact_urls,omitempty"` // JSON-encoded map, see models.BuildResult
+	Resources             *models.ResourceProfile `json:"resources,omitempty"`
+}
+
+// CompleteBuild reports requestHash as completed.
+func (c *Client) CompleteBuild(ctx context.Context, requestHash string, req CompleteBuildRequest) error {
+	req.WorkerID = c.workerID
+
+	body, err := json.Marshal(req)
+	if err != nil {
+		return fmt.Errorf("failed to marshal complete-build request: %w", err)
+	}
+
+	resp, err := c.do(ctx, http.MethodPost, c.baseURL+"/api/v1/internal/build/"+requestHash+"/complete", body)
+	if err != nil {
+		return fmt.Errorf("failed to reach complete-build endpoint: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusNoContent {
+		return fmt.Errorf("complete-build request failed: %s", resp.Status)
+	}
+	return nil
+}
+
+// FailBuild reports requestHash as failed with errMsg, classified as class
+// (see failures.Classify) so the server can decide whether to requeue it.
+func (c *Client) FailBuild(ctx context.Context, requestHash, errMsg string, class models.FailureClass) error {
+	body, err := json.Marshal(struct {
+		WorkerID     string              `json:"worker_id"`
+		ErrorMessage string              `json:"error_message"`
+		Class        models.FailureClass `json:"class"`
+	}{c.workerID, errMsg, class})
+	if err != nil {
+		return fmt.Errorf("failed to marshal fail-build request: %w", err)
+	}
+
+	resp, err := c.do(ctx, http.MethodPost, c.baseURL+"/api/v1/internal/build/"+requestHash+"/fail", body)
+	if err != nil {
+		return fmt.Errorf("failed to reach fail-build endpoint: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusNoContent {
+		return fmt.Errorf("fail-build request failed: %s", resp.Status)
+	}
+	return nil
+}
+
+// Heartbeat tells the server this runner is still alive and what, if
+// anything, it is currently building (see db.UpsertRunnerHeartbeat).
+func (c *Client) Heartbeat(ctx context.Context, currentRequestHash string) error {
+	body, err := json.Marshal(struct {
+		WorkerID           string `json:"worker_id"`
+		CurrentRequestHash string `json:"current_request_hash,omitempty"`
+	}{c.workerID, currentRequestHash})
+	if err != nil {
+		return fmt.Errorf("failed to marshal heartbeat: %w", err)
+	}
+
+	resp, err := c.do(ctx, http.MethodPost, c.baseURL+"/api/v1/internal/runners/heartbeat", body)
+	if err != nil {
+		return fmt.Errorf("failed to reach heartbeat endpoint: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusNoContent {
+		return fmt.Errorf("heartbeat request failed: %s", resp.Status)
+	}
+	return nil
+}
+
+func (c *Client) do(ctx context.Context, method, url string, body []byte) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, url, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if c.workerToken != "" {
+		req.Header.Set("X-Worker-Token", c.workerToken)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	// Error responses carry a JSON {"error": "..."} body; surface it in the
+	// returned error instead of forcing every call site to decode it itself.
+	if resp.StatusCode >= http.StatusBadRequest {
+		defer resp.Body.Close()
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		return nil, fmt.Errorf("%s: %s", resp.Status, msg)
+	}
+
+	return resp, nil
+}
@@ -0,0 +1,60 @@
+package signer
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+
+	"golang.org/x/crypto/openpgp"
+)
+
+// GPGSigner signs artifacts with a configured OpenPGP private key,
+// producing ASCII-armored detached signatures ("asc") compatible with
+// `gpg --verify`.
+type GPGSigner struct {
+	entity *openpgp.Entity
+}
+
+// NewGPGSigner loads the ASCII-armored private key at keyPath and unlocks
+// it with passphrase (if it's passphrase-protected), for producing
+// detached signatures with (*GPGSigner).Sign.
+func NewGPGSigner(keyPath, passphrase string) (*GPGSigner, error) {
+	if keyPath == "" {
+		return nil, fmt.Errorf("signing_gpg_key_path is required for the gpg signing backend")
+	}
+
+	f, err := os.Open(keyPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open signing key %s: %w", keyPath, err)
+	}
+	defer f.Close()
+
+	entities, err := openpgp.ReadArmoredKeyRing(f)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read signing key %s: %w", keyPath, err)
+	}
+	if len(entities) == 0 {
+		return nil, fmt.Errorf("signing key %s contains no keys", keyPath)
+	}
+	entity := entities[0]
+
+	if entity.PrivateKey != nil && entity.PrivateKey.Encrypted {
+		if err := entity.PrivateKey.Decrypt([]byte(passphrase)); err != nil {
+			return nil, fmt.Errorf("failed to unlock signing key %s: %w", keyPath, err)
+		}
+	}
+
+	return &GPGSigner{entity: entity}, nil
+}
+
+// Sign returns an ASCII-armored detached signature over data under the
+// "asc" part, and the signing key's hex-encoded fingerprint.
+func (s *GPGSigner) Sign(data []byte) (map[string][]byte, string, error) {
+	var sig bytes.Buffer
+	if err := openpgp.ArmoredDetachSign(&sig, s.entity, bytes.NewReader(data), nil); err != nil {
+		return nil, "", fmt.Errorf("failed to sign artifact: %w", err)
+	}
+
+	fingerprint := fmt.Sprintf("%X", s.entity.PrimaryKey.Fingerprint)
+	return map[string][]byte{"asc": sig.Bytes()}, fingerprint, nil
+}
@@ -0,0 +1,49 @@
+// Package signer produces detached signatures for completed firmware
+// artifacts, so a client can verify an image before flashing it instead of
+// trusting the download transport alone.
+package signer
+
+import "fmt"
+
+// Signer produces a detached signature bundle for an artifact. Different
+// backends need different files alongside the artifact — a single
+// ASCII-armored file for GPG, a signature plus a certificate for
+// sigstore's keyless signing — so Sign returns a set of named parts
+// instead of a single blob.
+type Signer interface {
+	// Sign returns data's signature as one or more named parts (the map
+	// key is the file suffix to store it under, e.g. "asc" or "sig"), and
+	// a string identifying the key or certificate that produced it — a
+	// GPG fingerprint, or a sigstore certificate's signing identity.
+	Sign(data []byte) (parts map[string][]byte, identity string, err error)
+}
+
+// Config is the subset of config.Config the signer package needs, kept
+// separate so this package doesn't import config (matches how the
+// container package takes its own narrow options structs instead of the
+// whole Config).
+type Config struct {
+	Backend string // "", "gpg", or "sigstore"
+
+	GPGKeyPath    string
+	GPGPassphrase string
+
+	SigstoreFulcioURL         string
+	SigstoreRekorURL          string
+	SigstoreIdentityTokenPath string
+}
+
+// New returns the Signer selected by cfg.Backend, or nil if signing is
+// disabled (the default).
+func New(cfg Config) (Signer, error) {
+	switch cfg.Backend {
+	case "":
+		return nil, nil
+	case "gpg":
+		return NewGPGSigner(cfg.GPGKeyPath, cfg.GPGPassphrase)
+	case "sigstore":
+		return NewSigstoreSigner(cfg.SigstoreFulcioURL, cfg.SigstoreRekorURL, cfg.SigstoreIdentityTokenPath)
+	default:
+		return nil, fmt.Errorf("unknown signing backend: %s", cfg.Backend)
+	}
+}
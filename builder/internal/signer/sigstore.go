@@ -0,0 +1,190 @@
+package signer
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"time"
+)
+
+// SigstoreSigner signs artifacts the way `cosign sign --keyless` does: a
+// fresh ECDSA keypair is generated for every signature, and a
+// Fulcio-compatible CA exchanges that keypair plus proof the caller holds
+// a valid OIDC identity token for a short-lived certificate binding the
+// key to that identity. No long-lived private key ever needs to be
+// stored on the worker. If a Rekor URL is configured, the signature is
+// also logged to the transparency log, best-effort.
+type SigstoreSigner struct {
+	fulcioURL         string
+	rekorURL          string
+	identityTokenPath string
+	httpClient        *http.Client
+}
+
+// NewSigstoreSigner returns a Signer that requests certificates from
+// fulcioURL (required) and, if rekorURL is set, logs every signature
+// there. identityTokenPath points at a file holding the OIDC identity
+// token presented to Fulcio.
+func NewSigstoreSigner(fulcioURL, rekorURL, identityTokenPath string) (*SigstoreSigner, error) {
+	if fulcioURL == "" {
+		return nil, fmt.Errorf("sigstore_fulcio_url is required for the sigstore signing backend")
+	}
+	if identityTokenPath == "" {
+		return nil, fmt.Errorf("sigstore_identity_token_path is required for the sigstore signing backend")
+	}
+
+	return &SigstoreSigner{
+		fulcioURL:         fulcioURL,
+		rekorURL:          rekorURL,
+		identityTokenPath: identityTokenPath,
+		httpClient:        &http.Client{Timeout: 30 * time.Second},
+	}, nil
+}
+
+// fulcioCertificateRequest is the body posted to fulcioURL: the DER-encoded
+// public half of the ephemeral keypair, a signature over the identity
+// token proving the caller holds its private half, and the identity token
+// itself for Fulcio to verify and bind into the issued certificate.
+type fulcioCertificateRequest struct {
+	PublicKey         []byte `json:"public_key"`
+	ProofOfPossession []byte `json:"proof_of_possession"`
+	IdentityToken     string `json:"identity_token"`
+}
+
+// fulcioCertificateResponse is Fulcio's reply: a DER-encoded leaf
+// certificate binding PublicKey to Identity.
+type fulcioCertificateResponse struct {
+	Certificate []byte `json:"certificate"`
+	Identity    string `json:"identity"`
+}
+
+// Sign signs data with a fresh ephemeral keypair, exchanges it for a
+// short-lived Fulcio certificate, and returns the base64-encoded
+// signature under "sig" and the PEM-encoded certificate under "cert",
+// alongside the identity the certificate was issued to.
+func (s *SigstoreSigner) Sign(data []byte) (map[string][]byte, string, error) {
+	priv, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to generate ephemeral signing key: %w", err)
+	}
+
+	digest := sha256.Sum256(data)
+	sig, err := ecdsa.SignASN1(rand.Reader, priv, digest[:])
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to sign artifact: %w", err)
+	}
+
+	certDER, identity, err := s.requestCertificate(priv)
+	if err != nil {
+		return nil, "", err
+	}
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: certDER})
+
+	if s.rekorURL != "" {
+		// A transparency log failure shouldn't block shipping an already
+		// correctly signed artifact, so this is logged and otherwise
+		// ignored.
+		if err := s.logToRekor(sig, certPEM); err != nil {
+			log.Printf("sigstore: failed to log signature to Rekor: %v", err)
+		}
+	}
+
+	return map[string][]byte{
+		"sig":  []byte(base64.StdEncoding.EncodeToString(sig)),
+		"cert": certPEM,
+	}, identity, nil
+}
+
+func (s *SigstoreSigner) requestCertificate(priv *ecdsa.PrivateKey) (certDER []byte, identity string, err error) {
+	token, err := os.ReadFile(s.identityTokenPath)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to read sigstore identity token: %w", err)
+	}
+
+	pubDER, err := x509.MarshalPKIXPublicKey(&priv.PublicKey)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to marshal ephemeral public key: %w", err)
+	}
+
+	tokenDigest := sha256.Sum256(bytes.TrimSpace(token))
+	proof, err := ecdsa.SignASN1(rand.Reader, priv, tokenDigest[:])
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to sign proof of possession: %w", err)
+	}
+
+	reqBody, err := json.Marshal(fulcioCertificateRequest{
+		PublicKey:         pubDER,
+		ProofOfPossession: proof,
+		IdentityToken:     string(bytes.TrimSpace(token)),
+	})
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to marshal certificate request: %w", err)
+	}
+
+	httpReq, err := http.NewRequest("POST", s.fulcioURL+"/api/v2/signingCert", bytes.NewReader(reqBody))
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to create certificate request: %w", err)
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	resp, err := s.httpClient.Do(httpReq)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to reach Fulcio at %s: %w", s.fulcioURL, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, "", fmt.Errorf("Fulcio returned status %d", resp.StatusCode)
+	}
+
+	var certResp fulcioCertificateResponse
+	if err := json.NewDecoder(resp.Body).Decode(&certResp); err != nil {
+		return nil, "", fmt.Errorf("failed to decode Fulcio response: %w", err)
+	}
+
+	return certResp.Certificate, certResp.Identity, nil
+}
+
+// rekorLogEntry is the body posted to rekorURL to record a signature in
+// the transparency log.
+type rekorLogEntry struct {
+	Signature   string `json:"signature"`
+	Certificate []byte `json:"certificate"`
+}
+
+func (s *SigstoreSigner) logToRekor(sig []byte, certPEM []byte) error {
+	reqBody, err := json.Marshal(rekorLogEntry{
+		Signature:   base64.StdEncoding.EncodeToString(sig),
+		Certificate: certPEM,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal Rekor entry: %w", err)
+	}
+
+	httpReq, err := http.NewRequest("POST", s.rekorURL+"/api/v1/log/entries", bytes.NewReader(reqBody))
+	if err != nil {
+		return fmt.Errorf("failed to create Rekor request: %w", err)
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	resp, err := s.httpClient.Do(httpReq)
+	if err != nil {
+		return fmt.Errorf("failed to reach Rekor at %s: %w", s.rekorURL, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
+		return fmt.Errorf("Rekor returned status %d", resp.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,150 @@
+package signer
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"fmt"
+	"os"
+
+	"golang.org/x/crypto/openpgp"
+)
+
+// Verifier checks a detached signature against the trusted keys
+// configured for POST /api/v1/verify, independently of which backend
+// produced it.
+type Verifier struct {
+	keyring openpgp.EntityList
+
+	// sigstoreRoots is the pool a sigstore certificate's chain must verify
+	// against (see NewVerifier's sigstoreRootCAPath). Nil disables sigstore
+	// verification: Verify rejects any certificate signature rather than
+	// trust a certificate nobody vouched for.
+	sigstoreRoots *x509.CertPool
+	// allowedIdentities restricts sigstore verification to certificates
+	// whose signing identity is in this set. Nil allows any identity that
+	// chains to sigstoreRoots.
+	allowedIdentities map[string]bool
+}
+
+// NewVerifier loads the ASCII-armored keyring at trustedKeysPath used to
+// check GPG signatures, and the PEM-encoded root CA at sigstoreRootCAPath
+// used to check that a sigstore certificate chains to a trusted root.
+// Either path may be empty to disable that backend; allowedIdentities, if
+// non-empty, restricts sigstore verification to those signing identities.
+func NewVerifier(trustedKeysPath, sigstoreRootCAPath string, allowedIdentities []string) (*Verifier, error) {
+	v := &Verifier{}
+
+	if trustedKeysPath != "" {
+		f, err := os.Open(trustedKeysPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open trusted keys %s: %w", trustedKeysPath, err)
+		}
+		defer f.Close()
+
+		keyring, err := openpgp.ReadArmoredKeyRing(f)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read trusted keys %s: %w", trustedKeysPath, err)
+		}
+		v.keyring = keyring
+	}
+
+	if sigstoreRootCAPath != "" {
+		rootPEM, err := os.ReadFile(sigstoreRootCAPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read sigstore root CA %s: %w", sigstoreRootCAPath, err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(rootPEM) {
+			return nil, fmt.Errorf("sigstore root CA %s contains no valid PEM certificate", sigstoreRootCAPath)
+		}
+		v.sigstoreRoots = pool
+	}
+
+	if len(allowedIdentities) > 0 {
+		v.allowedIdentities = make(map[string]bool, len(allowedIdentities))
+		for _, id := range allowedIdentities {
+			v.allowedIdentities[id] = true
+		}
+	}
+
+	return v, nil
+}
+
+// Verify checks signature against data. signature is either an
+// ASCII-armored OpenPGP detached signature, checked against the
+// configured trusted keyring, or a base64-encoded sigstore signature
+// accompanied by its PEM-encoded certificate, checked against that
+// certificate's public key. It returns the identity (GPG fingerprint, or
+// the sigstore certificate's signing identity) that produced a valid
+// signature.
+func (v *Verifier) Verify(data, signature, certificate []byte) (identity string, err error) {
+	if len(certificate) > 0 {
+		return v.verifySigstore(data, signature, certificate)
+	}
+	return v.verifyGPG(data, signature)
+}
+
+func (v *Verifier) verifyGPG(data, signature []byte) (string, error) {
+	if len(v.keyring) == 0 {
+		return "", fmt.Errorf("no trusted GPG keys are configured")
+	}
+
+	signer, err := openpgp.CheckArmoredDetachedSignature(v.keyring, bytes.NewReader(data), bytes.NewReader(signature))
+	if err != nil {
+		return "", fmt.Errorf("signature verification failed: %w", err)
+	}
+
+	return fmt.Sprintf("%X", signer.PrimaryKey.Fingerprint), nil
+}
+
+func (v *Verifier) verifySigstore(data, signature, certificate []byte) (string, error) {
+	if v.sigstoreRoots == nil {
+		return "", fmt.Errorf("sigstore verification is not configured (no trusted root CA)")
+	}
+
+	block, _ := pem.Decode(certificate)
+	if block == nil {
+		return "", fmt.Errorf("certificate is not valid PEM")
+	}
+
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse certificate: %w", err)
+	}
+
+	// Verify chains cert to v.sigstoreRoots and rejects it outside its
+	// validity period; KeyUsageAny sidesteps Verify's default requirement
+	// of the ServerAuth EKU, which a code-signing certificate won't have.
+	if _, err := cert.Verify(x509.VerifyOptions{
+		Roots:     v.sigstoreRoots,
+		KeyUsages: []x509.ExtKeyUsage{x509.ExtKeyUsageAny},
+	}); err != nil {
+		return "", fmt.Errorf("certificate does not chain to a trusted root: %w", err)
+	}
+
+	identity := cert.Subject.CommonName
+	if v.allowedIdentities != nil && !v.allowedIdentities[identity] {
+		return "", fmt.Errorf("signing identity %q is not allowed", identity)
+	}
+
+	pub, ok := cert.PublicKey.(*ecdsa.PublicKey)
+	if !ok {
+		return "", fmt.Errorf("certificate does not hold an ECDSA public key")
+	}
+
+	sig, err := base64.StdEncoding.DecodeString(string(bytes.TrimSpace(signature)))
+	if err != nil {
+		return "", fmt.Errorf("signature is not valid base64: %w", err)
+	}
+
+	digest := sha256.Sum256(data)
+	if !ecdsa.VerifyASN1(pub, digest[:], sig) {
+		return "", fmt.Errorf("signature verification failed")
+	}
+
+	return identity, nil
+}
@@ -0,0 +1,157 @@
+package telemetry
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// Metrics holds the Prometheus collectors this service exports. Every
+// collector lives on its own registry (not prometheus.DefaultRegisterer),
+// so Handler serves exactly what New registered and nothing pulled in by
+// an imported package's init().
+type Metrics struct {
+	registry *prometheus.Registry
+
+	httpRequestsTotal   *prometheus.CounterVec
+	httpRequestDuration *prometheus.HistogramVec
+
+	buildDuration *prometheus.HistogramVec
+
+	cacheHitsTotal   prometheus.Counter
+	cacheMissesTotal prometheus.Counter
+
+	diffPackagesTotal prometheus.Counter
+
+	queueLength prometheus.Gauge
+}
+
+// New creates and registers the metrics collectors, namespaced as
+// "<namespace>_<subsystem>_*" (e.g. "asu_builder_http_requests_total").
+func New(namespace, subsystem string) *Metrics {
+	registry := prometheus.NewRegistry()
+
+	m := &Metrics{
+		registry: registry,
+
+		httpRequestsTotal: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Name:      "http_requests_total",
+			Help:      "Total number of HTTP requests by method, path, and status.",
+		}, []string{"method", "path", "status"}),
+
+		httpRequestDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Name:      "http_request_duration_seconds",
+			Help:      "HTTP request latency by method, path, and status.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"method", "path", "status"}),
+
+		buildDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Name:      "build_duration_seconds",
+			Help:      "Firmware build duration by target, profile, and version.",
+			Buckets:   []float64{10, 30, 60, 120, 300, 600, 1200, 1800, 3600},
+		}, []string{"target", "profile", "version"}),
+
+		cacheHitsTotal: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Name:      "cache_hits_total",
+			Help:      "Total number of build requests served from a cached result.",
+		}),
+
+		cacheMissesTotal: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Name:      "cache_misses_total",
+			Help:      "Total number of build requests that required a build.",
+		}),
+
+		diffPackagesTotal: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Name:      "diff_packages_requests_total",
+			Help:      "Total number of build requests that used diff_packages.",
+		}),
+
+		queueLength: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Name:      "queue_length",
+			Help:      "Current number of pending build jobs.",
+		}),
+	}
+
+	registry.MustRegister(
+		m.httpRequestsTotal,
+		m.httpRequestDuration,
+		m.buildDuration,
+		m.cacheHitsTotal,
+		m.cacheMissesTotal,
+		m.diffPackagesTotal,
+		m.queueLength,
+	)
+
+	return m
+}
+
+// Handler serves the registered collectors in the Prometheus text format,
+// for GET /metrics.
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{})
+}
+
+// GinMiddleware records every request's count and latency by method,
+// route pattern (not the raw path, so path params don't explode the
+// cardinality), and status.
+func (m *Metrics) GinMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+
+		path := c.FullPath()
+		if path == "" {
+			path = "unmatched"
+		}
+		status := strconv.Itoa(c.Writer.Status())
+
+		m.httpRequestsTotal.WithLabelValues(c.Request.Method, path, status).Inc()
+		m.httpRequestDuration.WithLabelValues(c.Request.Method, path, status).Observe(time.Since(start).Seconds())
+	}
+}
+
+// RecordBuildDuration reports a completed build's wall-clock duration.
+func (m *Metrics) RecordBuildDuration(target, profile, version string, seconds float64) {
+	m.buildDuration.WithLabelValues(target, profile, version).Observe(seconds)
+}
+
+// RecordCacheHit reports a build request served from a cached result.
+func (m *Metrics) RecordCacheHit() {
+	m.cacheHitsTotal.Inc()
+}
+
+// RecordCacheMiss reports a build request that required a build.
+func (m *Metrics) RecordCacheMiss() {
+	m.cacheMissesTotal.Inc()
+}
+
+// RecordDiffPackagesUsage reports a build request that used diff_packages.
+func (m *Metrics) RecordDiffPackagesUsage() {
+	m.diffPackagesTotal.Inc()
+}
+
+// SetQueueLength reports the current pending job count (see
+// db.GetQueueLength), sampled after each enqueue by the build-request
+// handler rather than scraped from SQLite on every /metrics request; a
+// dequeue does not update it.
+func (m *Metrics) SetQueueLength(n int) {
+	m.queueLength.Set(float64(n))
+}
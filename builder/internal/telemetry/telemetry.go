@@ -0,0 +1,8 @@
+// Package telemetry is this service's observability surface: Prometheus
+// metrics (see Metrics, served at GET /metrics) and an OpenTelemetry trace
+// exporter (see InitTracing). Once InitTracing has run, every package
+// starts its own spans via otel.Tracer("internal/<package>") against the
+// global TracerProvider it installed, the same way every package logs
+// through slog.Default() once logging.New has run — neither needs the
+// provider threaded through as a parameter.
+package telemetry
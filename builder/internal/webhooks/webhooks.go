@@ -0,0 +1,161 @@
+// Package webhooks delivers terminal build events to a configured HTTP
+// endpoint, signing each payload so the receiver can verify it came from
+// this builder (see the events package for the bus deliveries are queued
+// from).
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"math"
+	"net/http"
+	"time"
+
+	"github.com/aparcar/asu/builder/internal/db"
+	"github.com/aparcar/asu/builder/internal/models"
+)
+
+// Policy bounds how many times a delivery is retried and how long it backs
+// off between attempts, matching db.RetryPolicy's shape for build job
+// retries.
+type Policy struct {
+	MaxAttempts    int
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+	Multiplier     float64
+}
+
+// backoffFor returns the delay before the next attempt after attemptCount
+// prior attempts, growing exponentially up to MaxBackoff.
+func (p Policy) backoffFor(attemptCount int) time.Duration {
+	backoff := float64(p.InitialBackoff) * math.Pow(p.Multiplier, float64(attemptCount))
+	if d := time.Duration(backoff); d < p.MaxBackoff {
+		return d
+	}
+	return p.MaxBackoff
+}
+
+// Deliverer POSTs queued webhook_deliveries rows to a single configured URL,
+// signing each body with HMAC-SHA256 and retrying failures with exponential
+// backoff until Policy.MaxAttempts is exhausted.
+type Deliverer struct {
+	db     *db.DB
+	url    string
+	secret string
+	policy Policy
+	client *http.Client
+}
+
+// New returns a Deliverer posting to url, or nil if url is empty (webhook
+// delivery disabled).
+func New(database *db.DB, url, secret string, policy Policy) *Deliverer {
+	if url == "" {
+		return nil
+	}
+	return &Deliverer{
+		db:     database,
+		url:    url,
+		secret: secret,
+		policy: policy,
+		client: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// Enqueue queues a delivery of eventType/requestHash's payload; the
+// background loop started by Start picks it up on its next poll.
+func (d *Deliverer) Enqueue(eventType, requestHash string, payload []byte) {
+	if _, err := d.db.CreateWebhookDelivery(eventType, requestHash, string(payload)); err != nil {
+		log.Printf("Failed to queue webhook delivery for %s: %v", requestHash, err)
+	}
+}
+
+// Start polls for due deliveries every interval and attempts them. It
+// blocks until ctx is cancelled, so callers should run it in a goroutine.
+func (d *Deliverer) Start(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	log.Printf("Webhook delivery started, polling every %s", interval)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			d.deliverDue()
+		}
+	}
+}
+
+func (d *Deliverer) deliverDue() {
+	due, err := d.db.GetDueWebhookDeliveries()
+	if err != nil {
+		log.Printf("Failed to list due webhook deliveries: %v", err)
+		return
+	}
+
+	for _, delivery := range due {
+		d.attempt(delivery)
+	}
+}
+
+func (d *Deliverer) attempt(delivery models.WebhookDelivery) {
+	err := d.post(delivery)
+	if err == nil {
+		if err := d.db.MarkWebhookDelivered(delivery.ID); err != nil {
+			log.Printf("Failed to mark webhook delivery %d delivered: %v", delivery.ID, err)
+		}
+		return
+	}
+
+	if delivery.AttemptCount+1 >= d.policy.MaxAttempts {
+		log.Printf("Webhook delivery %d for %s exhausted its retry budget: %v", delivery.ID, delivery.RequestHash, err)
+		if markErr := d.db.MarkWebhookFailed(delivery.ID, err.Error()); markErr != nil {
+			log.Printf("Failed to mark webhook delivery %d failed: %v", delivery.ID, markErr)
+		}
+		return
+	}
+
+	nextAttempt := time.Now().Add(d.policy.backoffFor(delivery.AttemptCount))
+	if markErr := d.db.MarkWebhookRetry(delivery.ID, err.Error(), nextAttempt); markErr != nil {
+		log.Printf("Failed to record webhook retry for delivery %d: %v", delivery.ID, markErr)
+	}
+}
+
+func (d *Deliverer) post(delivery models.WebhookDelivery) error {
+	body := []byte(delivery.Payload)
+
+	req, err := http.NewRequest(http.MethodPost, d.url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create webhook request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Asu-Event", delivery.EventType)
+	if d.secret != "" {
+		req.Header.Set("X-Asu-Signature", "sha256="+sign(d.secret, body))
+	}
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to reach webhook endpoint: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook endpoint returned status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// sign returns the hex-encoded HMAC-SHA256 of body keyed by secret, for the
+// X-Asu-Signature header.
+func sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,31 @@
+package webhooks
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPolicyBackoffFor(t *testing.T) {
+	policy := Policy{
+		MaxAttempts:    5,
+		InitialBackoff: 10 * time.Second,
+		MaxBackoff:     5 * time.Minute,
+		Multiplier:     2.0,
+	}
+
+	cases := []struct {
+		attemptCount int
+		want         time.Duration
+	}{
+		{0, 10 * time.Second},
+		{1, 20 * time.Second},
+		{2, 40 * time.Second},
+		{10, 5 * time.Minute}, // clamped to MaxBackoff
+	}
+
+	for _, c := range cases {
+		if got := policy.backoffFor(c.attemptCount); got != c.want {
+			t.Errorf("backoffFor(%d) = %v, want %v", c.attemptCount, got, c.want)
+		}
+	}
+}